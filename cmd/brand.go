@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	brandReference   string
+	brandTolerance   float64
+	brandMaxOffBrand float64
+	brandFormat      string
+	brandColors      int
+)
+
+// Exit codes of the brand command, so CI can tell violations from failures
+const (
+	brandExitViolation = 1
+	brandExitError     = 2
+)
+
+var brandCmd = &cobra.Command{
+	Use:   "brand [images...]",
+	Short: "Check image palettes against a reference brand palette.",
+	Long: `Check image palettes against a reference brand palette.
+
+The reference given with --reference is a GIMP palette (.gpl), a JSON file
+(.json) holding a list of hex colors, a list of {"name", "hex"} objects or an
+object of names to hex colors, or a text file with one hex color per line,
+optionally followed by its name.
+
+Every extracted palette color whose nearest brand color is more than
+--tolerance CIEDE2000 units away is off-brand. An image violates the brand when
+its off-brand colors cover more than --max-off-brand percent of it.
+
+The command exits with status 1 when any image violates the brand and 2 when
+an image or the reference can't be processed, so it can gate asset pipelines.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(brandExitError)
+		}
+		if !slices.Contains(output.BrandFormats(), brandFormat) {
+			fmt.Printf("invalid brand format: %s. Supported formats: %s\n", brandFormat, strings.Join(output.BrandFormats(), ", "))
+			os.Exit(brandExitError)
+		}
+		if brandTolerance < 0 || brandMaxOffBrand < 0 || brandMaxOffBrand > 100 {
+			fmt.Println("--tolerance must not be negative and --max-off-brand must be between 0 and 100")
+			os.Exit(brandExitError)
+		}
+		brand, err := output.ReadBrandPalette(brandReference)
+		if err != nil {
+			fmt.Printf("Error reading brand palette %s: %v\n", brandReference, err)
+			os.Exit(brandExitError)
+		}
+
+		results := colorsage.ExtractFiles(cmd.Context(), args, colorsage.Options{
+			Quantizers: quantizers,
+			NumColors:  brandColors,
+			Sequential: config.Sequential,
+		})
+
+		failed, violated := false, false
+		var reports []output.BrandReport
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing file %s: %v\n", result.FilePath, result.Err)
+				failed = true
+				continue
+			}
+			for _, quantizerName := range result.Quantizers {
+				if err, ok := result.Errors[quantizerName]; ok {
+					fmt.Fprintf(os.Stderr, "Error running %s on %s: %v\n", quantizerName, result.FilePath, err)
+					failed = true
+					continue
+				}
+				compliance, err := palette.CheckBrandCompliance(result.Results[quantizerName], brand, brandTolerance)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error checking %s on %s: %v\n", quantizerName, result.FilePath, err)
+					failed = true
+					continue
+				}
+				violation := compliance.OffBrandCoverage*100 > brandMaxOffBrand
+				violated = violated || violation
+				reports = append(reports, output.BrandReport{
+					File:       result.FilePath,
+					Quantizer:  quantizerName,
+					Compliance: compliance,
+					Violation:  violation,
+				})
+			}
+		}
+
+		write := func(w io.Writer) error {
+			return output.WriteBrandReports(w, brandFormat, reports)
+		}
+		if cmd.Flags().Changed("output") {
+			err = output.WriteFileAtomic(config.OutputFile, false, write)
+		} else {
+			err = write(os.Stdout)
+		}
+		if err != nil {
+			fmt.Println("Error writing brand report:", err)
+			failed = true
+		}
+
+		switch {
+		case failed:
+			os.Exit(brandExitError)
+		case violated:
+			os.Exit(brandExitViolation)
+		}
+	},
+}
+
+func init() {
+	brandCmd.Flags().StringVar(&brandReference, "reference", "", "Reference brand palette (.gpl, .json or a hex list).")
+	brandCmd.MarkFlagRequired("reference")
+	brandCmd.Flags().Float64Var(&brandTolerance, "tolerance", 5, "Largest CIEDE2000 difference to the nearest brand color that is still on-brand.")
+	brandCmd.Flags().Float64Var(&brandMaxOffBrand, "max-off-brand", 0, "Largest off-brand coverage, in percent, before an image violates the brand.")
+	brandCmd.Flags().StringVar(&brandFormat, "brand-format", "table", "Brand report format ("+strings.Join(output.BrandFormats(), ", ")+").")
+	brandCmd.Flags().IntVar(&brandColors, "colors", 8, "Number of palette colors to extract.")
+	rootCmd.AddCommand(brandCmd)
+}
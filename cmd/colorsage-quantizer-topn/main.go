@@ -0,0 +1,41 @@
+// Command colorsage-quantizer-topn is a minimal external quantizer that returns the
+// most frequent colors of the histogram. It doubles as a reference implementation of
+// the plugin protocol: install it on $PATH and run colorsage -q topn.
+package main
+
+import (
+	"colorsage/imageprocessor"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+func main() {
+	var request imageprocessor.PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, "decoding request:", err)
+		os.Exit(1)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(respond(request)); err != nil {
+		fmt.Fprintln(os.Stderr, "encoding response:", err)
+		os.Exit(1)
+	}
+}
+
+// respond answers a request with its most frequent colors
+func respond(request imageprocessor.PluginRequest) imageprocessor.PluginResponse {
+	if request.Version != imageprocessor.PluginProtocolVersion {
+		return imageprocessor.PluginResponse{Error: fmt.Sprintf("unsupported protocol version %d", request.Version)}
+	}
+
+	histogram := request.Histogram
+	sort.SliceStable(histogram, func(i, j int) bool {
+		return histogram[i].Count > histogram[j].Count
+	})
+	if len(histogram) > request.NumColors {
+		histogram = histogram[:request.NumColors]
+	}
+	return imageprocessor.PluginResponse{Palette: histogram}
+}
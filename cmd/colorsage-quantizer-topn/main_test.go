@@ -0,0 +1,56 @@
+package main
+
+import (
+	"colorsage/imageprocessor"
+	"reflect"
+	"testing"
+)
+
+func TestRespond(t *testing.T) {
+	histogram := []imageprocessor.PluginColor{
+		{Hex: "#000000", Count: 1},
+		{Hex: "#ff0000", Count: 5},
+		{Hex: "#00ff00", Count: 3},
+		{Hex: "#0000ff", Count: 3},
+	}
+
+	tests := []struct {
+		name    string
+		request imageprocessor.PluginRequest
+		want    imageprocessor.PluginResponse
+	}{
+		{
+			name:    "most frequent first, ties keep their order",
+			request: imageprocessor.PluginRequest{Version: imageprocessor.PluginProtocolVersion, NumColors: 3},
+			want: imageprocessor.PluginResponse{Palette: []imageprocessor.PluginColor{
+				{Hex: "#ff0000", Count: 5},
+				{Hex: "#00ff00", Count: 3},
+				{Hex: "#0000ff", Count: 3},
+			}},
+		},
+		{
+			name:    "fewer colors than requested",
+			request: imageprocessor.PluginRequest{Version: imageprocessor.PluginProtocolVersion, NumColors: 10},
+			want: imageprocessor.PluginResponse{Palette: []imageprocessor.PluginColor{
+				{Hex: "#ff0000", Count: 5},
+				{Hex: "#00ff00", Count: 3},
+				{Hex: "#0000ff", Count: 3},
+				{Hex: "#000000", Count: 1},
+			}},
+		},
+		{
+			name:    "unsupported version",
+			request: imageprocessor.PluginRequest{Version: 99, NumColors: 3},
+			want:    imageprocessor.PluginResponse{Error: "unsupported protocol version 99"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.Histogram = append([]imageprocessor.PluginColor(nil), histogram...)
+			if got := respond(tt.request); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("respond() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
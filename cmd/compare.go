@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareColors    int
+	compareDiffImage string
+	compareNoImage   bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [image-or-palette] [image-or-palette]",
+	Short: "Compare the palettes of two images or palette files.",
+	Long: `Compare the palettes of two images or palette files.
+
+Palette files are read like the brand command's --reference: a GIMP palette
+(.gpl), a JSON list or object of hex colors (.json), or a text file with one
+hex color per line. GIMP palettes written by colorsage keep their coverage;
+otherwise every palette color counts once. Images are reduced with a single
+quantizer.
+
+The distance between the palettes is the earth mover's distance over Lab,
+weighted by coverage: how far, on average, colors have to move to turn one
+palette into the other. Each color of the first palette is also matched with
+its nearest color in the second, with their CIEDE2000 difference.
+
+A side-by-side image of both sources and the matched swatches is written to
+--diff-image, by default next to the palette files of the first source.
+Palette files get no thumbnail.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(quantizers) > 1 {
+			fmt.Println("compare uses a single quantizer: pass one with -q, not a list or --all")
+			return
+		}
+
+		var colors [2]map[string]int
+		var sources [2]image.Image
+		for i, filePath := range args {
+			colors[i], sources[i], err = comparedPalette(cmd.Context(), filePath, quantizers[0])
+			if err != nil {
+				fmt.Printf("Error processing file %s: %v\n", filePath, err)
+				return
+			}
+		}
+
+		comparison, err := palette.ComparePalettes(colors[0], colors[1])
+		if err != nil {
+			fmt.Println("Error comparing palettes:", err)
+			return
+		}
+		output.DisplayComparison(os.Stdout, filepath.Base(args[0]), filepath.Base(args[1]), comparison)
+
+		if compareNoImage {
+			return
+		}
+		path := compareDiffImage
+		if path == "" {
+			namer, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir)
+			if err == nil {
+				other := filepath.Base(args[1])
+				path, err = namer.SourceName(args[0], "_vs_"+strings.TrimSuffix(other, filepath.Ext(other))+".compare.png")
+			}
+			if err != nil {
+				fmt.Println("Error writing comparison image:", err)
+				return
+			}
+		}
+		err = output.WriteFileAtomic(path, false, func(w io.Writer) error {
+			return output.WriteComparisonImage(w, sources[0], sources[1], comparison)
+		})
+		if err != nil {
+			fmt.Println("Error writing comparison image:", err)
+			return
+		}
+		fmt.Println("Comparison image:", path)
+	},
+}
+
+func init() {
+	compareCmd.Flags().IntVar(&compareColors, "colors", 8, "Number of palette colors to extract from images.")
+	compareCmd.Flags().StringVar(&compareDiffImage, "diff-image", "", "File to write the side-by-side comparison image to.")
+	compareCmd.Flags().BoolVar(&compareNoImage, "no-image", false, "Don't write the comparison image.")
+	rootCmd.AddCommand(compareCmd)
+}
+
+// comparedPalette extracts the palette of an image with the quantizer, returning the decoded image
+// too, or reads a palette file when the file isn't an image
+func comparedPalette(ctx context.Context, filePath string, quantizer imageprocessor.Quantizer) (map[string]int, image.Image, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	source, _, err := image.Decode(file)
+	file.Close()
+	if errors.Is(err, image.ErrFormat) {
+		colors, err := output.ReadPaletteColors(filePath)
+		return colors, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := colorsage.ExtractImage(ctx, source, colorsage.Options{
+		Quantizers: []imageprocessor.Quantizer{quantizer},
+		NumColors:  compareColors,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err, failed := result.Errors[quantizer.Name()]; failed {
+		return nil, nil, err
+	}
+	return result.Results[quantizer.Name()], source, nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	contrastFormat   string
+	contrastMinRatio float64
+	contrastColors   int
+)
+
+var contrastCmd = &cobra.Command{
+	Use:   "contrast [images...]",
+	Short: "Check the contrast between all colors of an image's palette.",
+	Long: `Check the contrast between all colors of an image's palette.
+
+For every pair of palette colors, the less frequent color is taken as text on
+the more frequent one. The report lists the WCAG 2.x contrast ratio with the
+AA and AAA verdicts for normal and large text, and the APCA Lc value.
+
+Pairs below --min-contrast get a suggestion: the text or background color,
+whichever changes least, moved in lightness until the pair reaches it.
+
+The report is printed to stdout, or written to the file given with --output.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !slices.Contains(output.ContrastFormats(), contrastFormat) {
+			fmt.Printf("invalid contrast format: %s. Supported formats: %s\n", contrastFormat, strings.Join(output.ContrastFormats(), ", "))
+			return
+		}
+
+		results := colorsage.ExtractFiles(cmd.Context(), args, colorsage.Options{
+			Quantizers: quantizers,
+			NumColors:  contrastColors,
+			Sequential: config.Sequential,
+		})
+
+		var reports []output.ContrastReport
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing file %s: %v\n", result.FilePath, result.Err)
+				continue
+			}
+			for _, quantizerName := range result.Quantizers {
+				if err, failed := result.Errors[quantizerName]; failed {
+					fmt.Fprintf(os.Stderr, "Error running %s on %s: %v\n", quantizerName, result.FilePath, err)
+					continue
+				}
+				reports = append(reports, output.ContrastReport{
+					File:      result.FilePath,
+					Quantizer: quantizerName,
+					MinRatio:  contrastMinRatio,
+					Pairs:     palette.AnalyzeContrast(result.Results[quantizerName], contrastMinRatio),
+				})
+			}
+		}
+
+		write := func(w io.Writer) error {
+			return output.WriteContrastReports(w, contrastFormat, reports)
+		}
+		if cmd.Flags().Changed("output") {
+			err = output.WriteFileAtomic(config.OutputFile, false, write)
+		} else {
+			err = write(os.Stdout)
+		}
+		if err != nil {
+			fmt.Println("Error writing contrast report:", err)
+		}
+	},
+}
+
+func init() {
+	contrastCmd.Flags().StringVar(&contrastFormat, "contrast-format", "table", "Contrast report format ("+strings.Join(output.ContrastFormats(), ", ")+").")
+	contrastCmd.Flags().Float64Var(&contrastMinRatio, "min-contrast", palette.WCAGAANormal, "WCAG contrast ratio that suggested adjustments reach.")
+	contrastCmd.Flags().IntVar(&contrastColors, "colors", 5, "Number of palette colors to extract.")
+	rootCmd.AddCommand(contrastCmd)
+}
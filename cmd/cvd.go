@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/palette"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cvdConditions []string
+	cvdMethod     string
+	cvdThreshold  float64
+	cvdColors     int
+	cvdImages     bool
+)
+
+var cvdCmd = &cobra.Command{
+	Use:   "cvd [images...]",
+	Short: "Simulate color vision deficiencies on palettes and check they stay distinguishable.",
+	Long: `Simulate color vision deficiencies on palettes and check they stay distinguishable.
+
+Each selected quantizer's palette is shown as perceived with protanopia,
+deuteranopia, tritanopia and achromatopsia, and every pair of palette colors
+whose CIEDE2000 difference drops below --threshold is flagged. Simulations use
+the Machado, Brettel or Viénot model, chosen with --cvd-method.
+
+With --simulate-images, the source image and the palette are also written as
+they appear with each deficiency, next to the palette files.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		simulators := make([]*palette.CVDSimulator, len(cvdConditions))
+		for i, condition := range cvdConditions {
+			if simulators[i], err = palette.NewCVDSimulator(condition, cvdMethod); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		namer, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var reports []output.CVDReport
+		for _, filePath := range args {
+			source := decodeSource(filePath)
+			if source == nil {
+				fmt.Printf("Error processing file %s: can't decode image\n", filePath)
+				continue
+			}
+			result, err := colorsage.ExtractImage(cmd.Context(), source, colorsage.Options{
+				Quantizers: quantizers,
+				NumColors:  cvdColors,
+			})
+			if err != nil {
+				fmt.Printf("Error processing file %s: %v\n", filePath, err)
+				continue
+			}
+
+			if cvdImages {
+				for i, simulator := range simulators {
+					writeSimulation(namer, filePath, "."+cvdConditions[i]+".png", func(w io.Writer) error {
+						return png.Encode(w, simulator.SimulateImage(source))
+					})
+				}
+			}
+
+			for _, quantizerName := range result.Quantizers {
+				if err, failed := result.Errors[quantizerName]; failed {
+					fmt.Printf("Error running %s on %s: %v\n", quantizerName, filePath, err)
+					continue
+				}
+				colors := result.Results[quantizerName]
+				report := output.CVDReport{File: filePath, Quantizer: quantizerName, Colors: colors, Threshold: cvdThreshold}
+				for i, simulator := range simulators {
+					report.Conditions = append(report.Conditions, output.CVDCondition{
+						Name:      cvdConditions[i],
+						Simulated: simulatedHexes(simulator, colors),
+						Confused:  simulator.FindConfusedPairs(colors, cvdThreshold),
+					})
+
+					if cvdImages {
+						extension := "." + cvdConditions[i] + ".png"
+						if path, err := namer.Name(filePath, quantizerName, extension); err != nil {
+							fmt.Printf("Error generating simulated palette for %s: %v\n", filePath, err)
+						} else if err := output.WritePaletteFile(path, "png", simulator.SimulatePalette(colors), output.PaletteOptions{}); err != nil {
+							fmt.Printf("Error generating simulated palette for %s: %v\n", filePath, err)
+						}
+					}
+				}
+				reports = append(reports, report)
+			}
+		}
+
+		output.DisplayCVDReport(os.Stdout, reports)
+	},
+}
+
+func init() {
+	cvdCmd.Flags().StringSliceVar(&cvdConditions, "condition", palette.CVDConditions, "Comma-separated color vision deficiencies to simulate ("+strings.Join(palette.CVDConditions, ", ")+").")
+	cvdCmd.Flags().StringVar(&cvdMethod, "cvd-method", palette.CVDMachado, "Simulation model ("+strings.Join(palette.CVDMethods, ", ")+").")
+	cvdCmd.Flags().Float64Var(&cvdThreshold, "threshold", 10, "CIEDE2000 difference below which two simulated colors are flagged as indistinguishable.")
+	cvdCmd.Flags().IntVar(&cvdColors, "colors", 5, "Number of palette colors to extract.")
+	cvdCmd.Flags().BoolVar(&cvdImages, "simulate-images", false, "Write the source image and palettes as perceived with each deficiency.")
+	rootCmd.AddCommand(cvdCmd)
+}
+
+// simulatedHexes maps every palette color to the color perceived with a deficiency
+func simulatedHexes(simulator *palette.CVDSimulator, colors map[string]int) map[string]string {
+	simulated := make(map[string]string, len(colors))
+	for hex := range colors {
+		if c, err := colorful.Hex(hex); err == nil {
+			simulated[hex] = simulator.Simulate(c).Hex()
+		}
+	}
+	return simulated
+}
+
+// writeSimulation writes a file derived from the source image, reporting failures
+func writeSimulation(namer *output.PaletteNamer, filePath, suffix string, write func(w io.Writer) error) {
+	path, err := namer.SourceName(filePath, suffix)
+	if err == nil {
+		err = output.WriteFileAtomic(path, false, write)
+	}
+	if err != nil {
+		fmt.Printf("Error writing simulated image for %s: %v\n", filePath, err)
+	}
+}
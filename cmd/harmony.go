@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spf13/cobra"
+)
+
+var (
+	harmonySwatch  string
+	harmonySchemes []string
+	harmonySpace   string
+	harmonySteps   int
+	harmonyColors  int
+	harmonyImages  bool
+)
+
+var harmonyCmd = &cobra.Command{
+	Use:   "harmony [image]",
+	Short: "Generate color harmonies and ramps from an image's palette.",
+	Long: `Generate color harmonies and ramps from an image's palette.
+
+The base color is the dominant swatch of the palette of the quantizer given
+with -q (k-means by default), or the one picked with --swatch, given as a 1-based rank or a hex
+color. From it, complementary, split-complementary, analogous, triadic and
+tetradic schemes are built by rotating the hue, along with tint, shade and tone
+ramps. Everything is computed in Oklch, or HCL with --harmony-space hcl.
+
+The kit is printed as a table, and each scheme and ramp is written as a
+palette image next to the palette files unless --images=false is set.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if len(quantizers) > 1 {
+			fmt.Println("harmony uses a single quantizer: pass one with -q, not a list or --all")
+			return
+		}
+
+		result := colorsage.ExtractFile(cmd.Context(), args[0], colorsage.Options{
+			Quantizers: quantizers,
+			NumColors:  harmonyColors,
+		})
+		if result.Err != nil {
+			fmt.Printf("Error processing file %s: %v\n", args[0], result.Err)
+			return
+		}
+		quantizerName := result.Quantizers[0]
+		if err, failed := result.Errors[quantizerName]; failed {
+			fmt.Printf("Error running %s on %s: %v\n", quantizerName, args[0], err)
+			return
+		}
+
+		base, err := pickSwatch(result.Results[quantizerName], harmonySwatch)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var rows []output.HarmonyRow
+		for _, scheme := range harmonySchemes {
+			colors, err := palette.Harmony(base, scheme, harmonySpace)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			rows = append(rows, output.HarmonyRow{Name: scheme, Colors: hexes(colors)})
+		}
+		for _, ramp := range palette.HarmonyRamps {
+			colors, err := palette.HarmonyRamp(base, ramp, harmonySpace, harmonySteps)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			rows = append(rows, output.HarmonyRow{Name: ramp, Colors: hexes(colors)})
+		}
+
+		output.DisplayHarmony(os.Stdout, base.Hex(), rows)
+
+		if !harmonyImages {
+			return
+		}
+		namer, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, row := range rows {
+			path, err := namer.SourceName(args[0], ".harmony-"+row.Name+".png")
+			if err == nil {
+				err = output.GeneratePaletteImage(output.HarmonyPalette(row.Colors), path)
+			}
+			if err != nil {
+				fmt.Printf("Error generating %s palette for %s: %v\n", row.Name, args[0], err)
+			}
+		}
+	},
+}
+
+func init() {
+	harmonyCmd.Flags().StringVar(&harmonySwatch, "swatch", "1", "Base swatch, as a 1-based rank in the palette or a hex color.")
+	harmonyCmd.Flags().StringSliceVar(&harmonySchemes, "scheme", palette.HarmonySchemes, "Comma-separated harmony schemes ("+strings.Join(palette.HarmonySchemes, ", ")+").")
+	harmonyCmd.Flags().StringVar(&harmonySpace, "harmony-space", palette.SpaceOklch, "Color space harmonies and ramps are computed in ("+strings.Join(palette.HarmonySpaces, ", ")+").")
+	harmonyCmd.Flags().IntVar(&harmonySteps, "steps", 5, "Number of colors in each tint, shade and tone ramp.")
+	harmonyCmd.Flags().IntVar(&harmonyColors, "colors", 5, "Number of palette colors to extract.")
+	harmonyCmd.Flags().BoolVar(&harmonyImages, "images", true, "Write each scheme and ramp as a palette image.")
+	rootCmd.AddCommand(harmonyCmd)
+}
+
+// pickSwatch selects a palette color by 1-based rank, or parses a hex color
+func pickSwatch(colors map[string]int, choice string) (colorful.Color, error) {
+	if strings.HasPrefix(choice, "#") {
+		c, err := colorful.Hex(choice)
+		if err != nil {
+			return colorful.Color{}, fmt.Errorf("invalid swatch color %q: %v", choice, err)
+		}
+		return c, nil
+	}
+
+	swatches := imageprocessor.SortedSwatches(colors)
+	rank, err := strconv.Atoi(choice)
+	if err != nil || rank < 1 || rank > len(swatches) {
+		return colorful.Color{}, fmt.Errorf("invalid swatch: %s. Use a hex color or a rank from 1 to %d", choice, len(swatches))
+	}
+	return colorful.Hex(swatches[rank-1].Hex)
+}
+
+func hexes(colors []colorful.Color) []string {
+	out := make([]string, len(colors))
+	for i, c := range colors {
+		out[i] = c.Hex()
+	}
+	return out
+}
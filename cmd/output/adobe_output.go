@@ -0,0 +1,138 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/imageprocessor"
+	"encoding/binary"
+	"io"
+	"math"
+	"unicode/utf16"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Adobe Swatch Exchange block types
+const (
+	aseGroupStart = 0xC001
+	aseGroupEnd   = 0xC002
+	aseColorEntry = 0x0001
+)
+
+// Adobe color swatch (.aco) color spaces
+const (
+	acoRGB = 0
+	acoLab = 7
+)
+
+// writeASE writes a palette as an Adobe Swatch Exchange (.ase) file with a single named group.
+// Colors are stored in RGB, or in Lab (D50, L scaled to 0..1 as Adobe does) when opts.Lab is set.
+func writeASE(w io.Writer, palette map[string]int, opts PaletteOptions) error {
+	swatches := imageprocessor.SortedSwatches(palette)
+
+	var body bytes.Buffer
+	blocks := 0
+
+	writeBlock := func(blockType uint16, data []byte) {
+		binary.Write(&body, binary.BigEndian, blockType)
+		binary.Write(&body, binary.BigEndian, uint32(len(data)))
+		body.Write(data)
+		blocks++
+	}
+
+	var group bytes.Buffer
+	writeASEString(&group, opts.Name)
+	writeBlock(aseGroupStart, group.Bytes())
+
+	for _, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return err
+		}
+
+		var entry bytes.Buffer
+		writeASEString(&entry, swatch.Hex)
+		if opts.Lab {
+			l, a, b := c.LabWhiteRef(colorful.D50)
+			entry.WriteString("LAB ")
+			binary.Write(&entry, binary.BigEndian, [3]float32{float32(l), float32(a * 100), float32(b * 100)})
+		} else {
+			entry.WriteString("RGB ")
+			binary.Write(&entry, binary.BigEndian, [3]float32{float32(c.R), float32(c.G), float32(c.B)})
+		}
+		binary.Write(&entry, binary.BigEndian, uint16(2)) // Normal (process) color
+		writeBlock(aseColorEntry, entry.Bytes())
+	}
+
+	writeBlock(aseGroupEnd, nil)
+
+	var header bytes.Buffer
+	header.WriteString("ASEF")
+	binary.Write(&header, binary.BigEndian, [2]uint16{1, 0})
+	binary.Write(&header, binary.BigEndian, uint32(blocks))
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// writeASEString writes a length-prefixed, null-terminated UTF-16BE string
+func writeASEString(buf *bytes.Buffer, s string) {
+	units := append(utf16.Encode([]rune(s)), 0)
+	binary.Write(buf, binary.BigEndian, uint16(len(units)))
+	binary.Write(buf, binary.BigEndian, units)
+}
+
+// writeACO writes a palette as a Photoshop color swatch (.aco) file. ACO has no groups, so the
+// palette name prefixes each swatch name. Both the version 1 section and the version 2 section
+// with names are written, as Photoshop does. With opts.Lab, colors are stored in Lab (D50).
+func writeACO(w io.Writer, palette map[string]int, opts PaletteOptions) error {
+	swatches := imageprocessor.SortedSwatches(palette)
+
+	type acoColor struct {
+		values [5]uint16
+		name   string
+	}
+	colors := make([]acoColor, 0, len(swatches))
+	for _, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return err
+		}
+
+		var values [5]uint16
+		if opts.Lab {
+			l, a, b := c.LabWhiteRef(colorful.D50)
+			values = [5]uint16{acoLab, uint16(math.Round(l * 10000)), uint16(int16(math.Round(a * 10000))), uint16(int16(math.Round(b * 10000))), 0}
+		} else {
+			values = [5]uint16{acoRGB, uint16(math.Round(c.R * 65535)), uint16(math.Round(c.G * 65535)), uint16(math.Round(c.B * 65535)), 0}
+		}
+
+		name := swatch.Hex
+		if opts.Name != "" {
+			name = opts.Name + " " + swatch.Hex
+		}
+		colors = append(colors, acoColor{values: values, name: name})
+	}
+
+	var buf bytes.Buffer
+
+	// Version 1: colors only
+	binary.Write(&buf, binary.BigEndian, [2]uint16{1, uint16(len(colors))})
+	for _, c := range colors {
+		binary.Write(&buf, binary.BigEndian, c.values)
+	}
+
+	// Version 2: colors followed by their names
+	binary.Write(&buf, binary.BigEndian, [2]uint16{2, uint16(len(colors))})
+	for _, c := range colors {
+		binary.Write(&buf, binary.BigEndian, c.values)
+		units := append(utf16.Encode([]rune(c.name)), 0)
+		binary.Write(&buf, binary.BigEndian, uint32(len(units)))
+		binary.Write(&buf, binary.BigEndian, units)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,182 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// testPalette has distinct counts so that swatches are written in a known order. Saturated blue
+// has the most negative Lab b, which must survive as a signed value.
+var testPalette = map[string]int{"#ff0000": 30, "#00ff80": 20, "#202020": 10, "#f0e68c": 5, "#0000ff": 1}
+
+// testPaletteOrder is testPalette from most to least frequent
+var testPaletteOrder = []string{"#ff0000", "#00ff80", "#202020", "#f0e68c", "#0000ff"}
+
+const testPaletteName = "photo.png – KMeans"
+
+// binaryReader reads big-endian values, failing the test on short input
+type binaryReader struct {
+	t *testing.T
+	r io.Reader
+}
+
+func (br binaryReader) read(v interface{}) {
+	br.t.Helper()
+	if err := binary.Read(br.r, binary.BigEndian, v); err != nil {
+		br.t.Fatalf("reading %T: %v", v, err)
+	}
+}
+
+func (br binaryReader) uint16() uint16 {
+	br.t.Helper()
+	var v uint16
+	br.read(&v)
+	return v
+}
+
+func (br binaryReader) uint32() uint32 {
+	br.t.Helper()
+	var v uint32
+	br.read(&v)
+	return v
+}
+
+// utf16 reads count UTF-16BE code units and checks the trailing null
+func (br binaryReader) utf16(count int) string {
+	br.t.Helper()
+	units := make([]uint16, count)
+	br.read(units)
+	if count == 0 || units[count-1] != 0 {
+		br.t.Fatalf("string %v is not null-terminated", units)
+	}
+	return string(utf16.Decode(units[:count-1]))
+}
+
+func TestWriteASE(t *testing.T) {
+	for _, lab := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := writeASE(&buf, testPalette, PaletteOptions{Name: testPaletteName, Lab: lab}); err != nil {
+			t.Fatal(err)
+		}
+		br := binaryReader{t, &buf}
+
+		var signature [4]byte
+		br.read(&signature)
+		if string(signature[:]) != "ASEF" {
+			t.Fatalf("signature = %q, want ASEF", signature)
+		}
+		if major, minor := br.uint16(), br.uint16(); major != 1 || minor != 0 {
+			t.Errorf("version = %d.%d, want 1.0", major, minor)
+		}
+		if blocks := br.uint32(); blocks != uint32(len(testPalette)+2) {
+			t.Errorf("block count = %d, want %d", blocks, len(testPalette)+2)
+		}
+
+		if blockType := br.uint16(); blockType != aseGroupStart {
+			t.Fatalf("first block type = %#x, want group start", blockType)
+		}
+		br.uint32()
+		if name := br.utf16(int(br.uint16())); name != testPaletteName {
+			t.Errorf("group name = %q, want %q", name, testPaletteName)
+		}
+
+		for _, hex := range testPaletteOrder {
+			if blockType := br.uint16(); blockType != aseColorEntry {
+				t.Fatalf("block type = %#x, want color entry", blockType)
+			}
+			length := br.uint32()
+			name := br.utf16(int(br.uint16()))
+			if name != hex {
+				t.Errorf("color name = %q, want %q", name, hex)
+			}
+			if want := uint32(2 + 2*(len(hex)+1) + 4 + 12 + 2); length != want {
+				t.Errorf("%s: block length = %d, want %d", hex, length, want)
+			}
+
+			var model [4]byte
+			var values [3]float32
+			br.read(&model)
+			br.read(&values)
+			var c colorful.Color
+			switch {
+			case !lab && string(model[:]) == "RGB ":
+				c = colorful.Color{R: float64(values[0]), G: float64(values[1]), B: float64(values[2])}
+			case lab && string(model[:]) == "LAB ":
+				if values[0] < 0 || values[0] > 1 {
+					t.Errorf("%s: L = %g, want it scaled to 0..1", hex, values[0])
+				}
+				c = colorful.LabWhiteRef(float64(values[0]), float64(values[1])/100, float64(values[2])/100, colorful.D50)
+			default:
+				t.Fatalf("%s: color model %q with lab=%v", hex, model, lab)
+			}
+			if got := c.Clamped().Hex(); got != hex {
+				t.Errorf("decoded color = %s, want %s (lab=%v)", got, hex, lab)
+			}
+			if colorType := br.uint16(); colorType != 2 {
+				t.Errorf("%s: color type = %d, want 2 (normal)", hex, colorType)
+			}
+		}
+
+		if blockType, length := br.uint16(), br.uint32(); blockType != aseGroupEnd || length != 0 {
+			t.Errorf("last block = %#x with length %d, want an empty group end", blockType, length)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%d trailing bytes", buf.Len())
+		}
+	}
+}
+
+func TestWriteACO(t *testing.T) {
+	for _, lab := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := writeACO(&buf, testPalette, PaletteOptions{Name: testPaletteName, Lab: lab}); err != nil {
+			t.Fatal(err)
+		}
+		br := binaryReader{t, &buf}
+
+		// The version 1 section is followed by the version 2 section with the same colors and names
+		for _, version := range []uint16{1, 2} {
+			if got := br.uint16(); got != version {
+				t.Fatalf("section version = %d, want %d", got, version)
+			}
+			if count := br.uint16(); count != uint16(len(testPalette)) {
+				t.Fatalf("version %d color count = %d, want %d", version, count, len(testPalette))
+			}
+
+			for _, hex := range testPaletteOrder {
+				var values [5]uint16
+				br.read(&values)
+				if values[4] != 0 {
+					t.Errorf("%s: fourth component = %d, want 0", hex, values[4])
+				}
+
+				var c colorful.Color
+				switch {
+				case !lab && values[0] == acoRGB:
+					c = colorful.Color{R: float64(values[1]) / 65535, G: float64(values[2]) / 65535, B: float64(values[3]) / 65535}
+				case lab && values[0] == acoLab:
+					c = colorful.LabWhiteRef(float64(values[1])/10000, float64(int16(values[2]))/10000, float64(int16(values[3]))/10000, colorful.D50)
+				default:
+					t.Fatalf("%s: color space %d with lab=%v", hex, values[0], lab)
+				}
+				if got := c.Clamped().Hex(); got != hex {
+					t.Errorf("version %d decoded color = %s, want %s (lab=%v)", version, got, hex, lab)
+				}
+
+				if version == 2 {
+					if name, want := br.utf16(int(br.uint32())), testPaletteName+" "+hex; name != want {
+						t.Errorf("name = %q, want %q", name, want)
+					}
+				}
+			}
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%d trailing bytes", buf.Len())
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package output
+
+import (
+	"bufio"
+	"colorsage/palette"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ReadBrandPalette reads a reference brand palette from a GIMP palette (.gpl), a JSON file (.json)
+// or a text file with one hex color per line, optionally followed by its name
+func ReadBrandPalette(filePath string) ([]palette.BrandColor, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".gpl":
+		gpl, err := ReadGPL(file)
+		if err != nil {
+			return nil, err
+		}
+		brand := make([]palette.BrandColor, len(gpl.Swatches))
+		for i, swatch := range gpl.Swatches {
+			brand[i] = palette.BrandColor{Name: swatch.Name, Hex: swatch.Hex}
+		}
+		return brand, nil
+	case ".json":
+		return readBrandJSON(file)
+	default:
+		return readBrandHexList(file)
+	}
+}
+
+// ReadPaletteColors reads a palette file accepted by ReadBrandPalette into a color map. GIMP palettes
+// keep the coverage written with them; in the other formats each entry counts once.
+func ReadPaletteColors(filePath string) (map[string]int, error) {
+	if strings.EqualFold(filepath.Ext(filePath), ".gpl") {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		gpl, err := ReadGPL(file)
+		if err != nil {
+			return nil, err
+		}
+		return gpl.Colors(), nil
+	}
+
+	brand, err := ReadBrandPalette(filePath)
+	if err != nil {
+		return nil, err
+	}
+	colors := make(map[string]int, len(brand))
+	for _, entry := range brand {
+		colors[entry.Hex]++
+	}
+	return colors, nil
+}
+
+// readBrandJSON accepts a list of hex strings, a list of {"name", "hex"} objects or an object
+// mapping names to hex strings
+func readBrandJSON(r io.Reader) ([]palette.BrandColor, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	var hexes []string
+	if err := json.Unmarshal(raw, &hexes); err == nil {
+		brand := make([]palette.BrandColor, len(hexes))
+		for i, hex := range hexes {
+			brand[i] = palette.BrandColor{Hex: hex}
+		}
+		return validBrandColors(brand)
+	}
+
+	var entries []struct {
+		Name string `json:"name"`
+		Hex  string `json:"hex"`
+	}
+	if err := json.Unmarshal(raw, &entries); err == nil {
+		brand := make([]palette.BrandColor, len(entries))
+		for i, entry := range entries {
+			brand[i] = palette.BrandColor{Name: entry.Name, Hex: entry.Hex}
+		}
+		return validBrandColors(brand)
+	}
+
+	var named map[string]string
+	if err := json.Unmarshal(raw, &named); err == nil {
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		brand := make([]palette.BrandColor, len(names))
+		for i, name := range names {
+			brand[i] = palette.BrandColor{Name: name, Hex: named[name]}
+		}
+		return validBrandColors(brand)
+	}
+
+	return nil, fmt.Errorf("unsupported brand palette JSON: expected a list of hex colors, a list of {\"name\", \"hex\"} objects or an object of names to hex colors")
+}
+
+// readBrandHexList reads one "#rrggbb [name]" color per line, skipping blank lines and // comments
+func readBrandHexList(r io.Reader) ([]palette.BrandColor, error) {
+	var brand []palette.BrandColor
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+		hex, name, _ := strings.Cut(text, " ")
+		brand = append(brand, palette.BrandColor{Name: strings.TrimSpace(name), Hex: hex})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return validBrandColors(brand)
+}
+
+// validBrandColors normalizes the hex codes of a brand palette, failing on invalid ones
+func validBrandColors(brand []palette.BrandColor) ([]palette.BrandColor, error) {
+	for i, entry := range brand {
+		hex := entry.Hex
+		if !strings.HasPrefix(hex, "#") {
+			hex = "#" + hex
+		}
+		c, err := colorful.Hex(hex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid brand color %q", entry.Hex)
+		}
+		brand[i].Hex = c.Hex()
+	}
+	if len(brand) == 0 {
+		return nil, fmt.Errorf("brand palette is empty")
+	}
+	return brand, nil
+}
+
+// BrandReport holds the brand compliance of one quantizer's palette
+type BrandReport struct {
+	File       string
+	Quantizer  string
+	Compliance palette.BrandCompliance
+	Violation  bool // The off-brand coverage exceeds what is allowed
+}
+
+type jsonBrandReport struct {
+	File             string               `json:"file"`
+	Quantizer        string               `json:"quantizer"`
+	Compliant        bool                 `json:"compliant"`
+	OffBrandCoverage float64              `json:"off_brand_percentage"`
+	OffBrand         []jsonOffBrandSwatch `json:"off_brand"`
+}
+
+type jsonOffBrandSwatch struct {
+	Hex         string  `json:"hex"`
+	Percentage  float64 `json:"percentage"`
+	NearestHex  string  `json:"nearest_hex"`
+	NearestName string  `json:"nearest_name,omitempty"`
+	DeltaE      float64 `json:"delta_e"`
+}
+
+// brandWriters maps brand report formats to their writers
+var brandWriters = map[string]func(w io.Writer, reports []BrandReport) error{
+	"table": writeBrandTable,
+	"json":  writeBrandJSON,
+}
+
+// BrandFormats returns the names of all supported brand report formats
+func BrandFormats() []string {
+	names := make([]string, 0, len(brandWriters))
+	for name := range brandWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteBrandReports writes brand reports in the named format
+func WriteBrandReports(w io.Writer, format string, reports []BrandReport) error {
+	writer, ok := brandWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown brand format %q. Supported formats: %s", format, strings.Join(BrandFormats(), ", "))
+	}
+	return writer(w, reports)
+}
+
+func writeBrandTable(w io.Writer, reports []BrandReport) error {
+	for _, report := range reports {
+		status := Green + "on brand" + Reset
+		if report.Violation {
+			status = Red + "off brand" + Reset
+		}
+		fmt.Fprintf(w, "%s – %s: %s, %.1f%% off-brand coverage\n", report.File, report.Quantizer, status, report.Compliance.OffBrandCoverage*100)
+		if len(report.Compliance.OffBrand) == 0 {
+			fmt.Fprintln(w)
+			continue
+		}
+
+		table := tablewriter.NewWriter(w)
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(false)
+		table.SetHeader([]string{"Color", "Coverage", "Nearest Brand Color", "ΔE2000"})
+		for _, swatch := range report.Compliance.OffBrand {
+			nearest := BackgroundColor(swatch.Nearest.Hex) + swatch.Nearest.Hex + Reset
+			if swatch.Nearest.Name != "" {
+				nearest += " " + swatch.Nearest.Name
+			}
+			table.Append([]string{
+				BackgroundColor(swatch.Hex) + swatch.Hex + Reset,
+				fmt.Sprintf("%.1f%%", swatch.Coverage*100),
+				nearest,
+				fmt.Sprintf("%.2f", swatch.DeltaE),
+			})
+		}
+		table.Render()
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func writeBrandJSON(w io.Writer, reports []BrandReport) error {
+	document := make([]jsonBrandReport, 0, len(reports))
+	for _, report := range reports {
+		entry := jsonBrandReport{
+			File:             report.File,
+			Quantizer:        report.Quantizer,
+			Compliant:        !report.Violation,
+			OffBrandCoverage: round(report.Compliance.OffBrandCoverage*100, 2),
+			OffBrand:         []jsonOffBrandSwatch{},
+		}
+		for _, swatch := range report.Compliance.OffBrand {
+			entry.OffBrand = append(entry.OffBrand, jsonOffBrandSwatch{
+				Hex:         swatch.Hex,
+				Percentage:  round(swatch.Coverage*100, 2),
+				NearestHex:  swatch.Nearest.Hex,
+				NearestName: swatch.Nearest.Name,
+				DeltaE:      round(swatch.DeltaE, 2),
+			})
+		}
+		document = append(document, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
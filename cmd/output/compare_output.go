@@ -0,0 +1,90 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/olekukonko/tablewriter"
+	xdraw "golang.org/x/image/draw"
+)
+
+// Sizes of the comparison image
+const (
+	compareColumnWidth = 160
+	compareRowHeight   = 32
+)
+
+// DisplayComparison prints the earth mover's distance and the matched swatches of two palettes
+func DisplayComparison(w io.Writer, nameA, nameB string, comparison palette.PaletteComparison) {
+	fmt.Fprintf(w, "Earth mover's distance between %s and %s: %.2f\n", nameA, nameB, comparison.EMD)
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{nameA, "Coverage", nameB, "Coverage", "ΔE2000"})
+	for _, match := range comparison.Matches {
+		table.Append([]string{
+			BackgroundColor(match.A) + match.A + Reset,
+			fmt.Sprintf("%.1f%%", match.CoverageA*100),
+			BackgroundColor(match.B) + match.B + Reset,
+			fmt.Sprintf("%.1f%%", match.CoverageB*100),
+			fmt.Sprintf("%.2f", match.DeltaE),
+		})
+	}
+	table.Render()
+}
+
+// WriteComparisonImage draws the sources side by side, leaving out nil ones, above one row per matched
+// swatch: the color of A, its match in B and their difference
+func WriteComparisonImage(w io.Writer, sourceA, sourceB image.Image, comparison palette.PaletteComparison) error {
+	width := 3 * compareColumnWidth
+	thumbWidth := width / 2
+
+	// Thumbnails get the height of the taller one at half the image width; a missing source
+	// leaves its half blank
+	thumbHeight := 0
+	for _, source := range []image.Image{sourceA, sourceB} {
+		if source != nil && source.Bounds().Dx() > 0 {
+			bounds := source.Bounds()
+			thumbHeight = max(thumbHeight, int(math.Round(float64(bounds.Dy())*float64(thumbWidth)/float64(bounds.Dx()))))
+		}
+	}
+
+	img := newCanvas(width, thumbHeight+compareRowHeight*(len(comparison.Matches)+1))
+	for i, source := range []image.Image{sourceA, sourceB} {
+		if source == nil || source.Bounds().Dx() == 0 {
+			continue
+		}
+		bounds := source.Bounds()
+		height := int(math.Round(float64(bounds.Dy()) * float64(thumbWidth) / float64(bounds.Dx())))
+		target := image.Rect(i*thumbWidth, (thumbHeight-height)/2, (i+1)*thumbWidth, (thumbHeight-height)/2+height)
+		xdraw.CatmullRom.Scale(img, target, source, bounds, xdraw.Src, nil)
+	}
+
+	header := image.Rect(0, thumbHeight, width, thumbHeight+compareRowHeight)
+	drawCenteredText(img, header, fmt.Sprintf("EMD %.2f", comparison.EMD), color.Black)
+
+	// Coverage is drawn in labels as a share of 10000
+	const total = 10000
+	for i, match := range comparison.Matches {
+		y := thumbHeight + compareRowHeight*(i+1)
+		swatchA := imageprocessor.Swatch{Hex: match.A, Count: int(math.Round(match.CoverageA * total))}
+		swatchB := imageprocessor.Swatch{Hex: match.B, Count: int(math.Round(match.CoverageB * total))}
+		labels := PaletteOptions{Labels: true}
+		if err := drawSwatch(img, image.Rect(0, y, compareColumnWidth, y+compareRowHeight), swatchA, total, labels); err != nil {
+			return err
+		}
+		if err := drawSwatch(img, image.Rect(compareColumnWidth, y, 2*compareColumnWidth, y+compareRowHeight), swatchB, total, labels); err != nil {
+			return err
+		}
+		drawCenteredText(img, image.Rect(2*compareColumnWidth, y, width, y+compareRowHeight), fmt.Sprintf("dE %.2f", match.DeltaE), color.Black)
+	}
+
+	return png.Encode(w, img)
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/palette"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+func TestWriteComparisonImage(t *testing.T) {
+	red := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	draw.Draw(red, red.Bounds(), &image.Uniform{C: color.RGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	comparison := palette.PaletteComparison{
+		EMD:     10,
+		Matches: []palette.SwatchMatch{{A: "#ff0000", B: "#00ff00", CoverageA: 1, CoverageB: 1, DeltaE: 80}},
+	}
+
+	width := 3 * compareColumnWidth
+	thumbHeight := 50 * (width / 2) / 100
+	tests := []struct {
+		name             string
+		sourceA, sourceB image.Image
+		wantHeight       int
+		wantLeft         color.Color // Middle of each thumbnail, if drawn
+		wantRight        color.Color
+	}{
+		{"both", red, red, thumbHeight + 2*compareRowHeight, red.At(0, 0), red.At(0, 0)},
+		{"first only", red, nil, thumbHeight + 2*compareRowHeight, red.At(0, 0), color.White},
+		{"second only", nil, red, thumbHeight + 2*compareRowHeight, color.White, red.At(0, 0)},
+		{"none", nil, nil, 2 * compareRowHeight, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteComparisonImage(&buf, tt.sourceA, tt.sourceB, comparison); err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Dy(); got != tt.wantHeight {
+				t.Errorf("height = %d, want %d", got, tt.wantHeight)
+			}
+			if tt.wantLeft == nil {
+				return
+			}
+			for _, side := range []struct {
+				x    int
+				want color.Color
+			}{{width / 4, tt.wantLeft}, {3 * width / 4, tt.wantRight}} {
+				if got, want := color.RGBAModel.Convert(img.At(side.x, thumbHeight/2)), color.RGBAModel.Convert(side.want); got != want {
+					t.Errorf("pixel at x %d = %v, want %v", side.x, got, want)
+				}
+			}
+		})
+	}
+}
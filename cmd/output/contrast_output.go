@@ -0,0 +1,180 @@
+package output
+
+import (
+	"colorsage/palette"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ContrastReport holds the contrast analysis of one quantizer's palette
+type ContrastReport struct {
+	File      string
+	Quantizer string
+	MinRatio  float64 // Ratio the suggestions reach
+	Pairs     []palette.ContrastPair
+}
+
+// jsonContrastReport is the JSON representation of a ContrastReport
+type jsonContrastReport struct {
+	File      string             `json:"file"`
+	Quantizer string             `json:"quantizer"`
+	MinRatio  float64            `json:"min_ratio"`
+	Pairs     []jsonContrastPair `json:"pairs"`
+}
+
+type jsonContrastPair struct {
+	Text       string           `json:"text"`
+	Background string           `json:"background"`
+	Ratio      float64          `json:"ratio"`
+	APCA       float64          `json:"apca_lc"`
+	AA         bool             `json:"aa"`
+	AALarge    bool             `json:"aa_large"`
+	AAA        bool             `json:"aaa"`
+	AAALarge   bool             `json:"aaa_large"`
+	Suggestion *jsonContrastFix `json:"suggestion,omitempty"`
+}
+
+type jsonContrastFix struct {
+	Role  string  `json:"role"`
+	Hex   string  `json:"hex"`
+	Ratio float64 `json:"ratio"`
+}
+
+// contrastWriters maps contrast report formats to their writers
+var contrastWriters = map[string]func(w io.Writer, reports []ContrastReport) error{
+	"table": writeContrastTable,
+	"json":  writeContrastJSON,
+	"csv":   writeContrastCSV,
+}
+
+// ContrastFormats returns the names of all supported contrast report formats
+func ContrastFormats() []string {
+	names := make([]string, 0, len(contrastWriters))
+	for name := range contrastWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteContrastReports writes contrast reports in the named format
+func WriteContrastReports(w io.Writer, format string, reports []ContrastReport) error {
+	writer, ok := contrastWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown contrast format %q. Supported formats: %s", format, strings.Join(ContrastFormats(), ", "))
+	}
+	return writer(w, reports)
+}
+
+func writeContrastTable(w io.Writer, reports []ContrastReport) error {
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s – %s (suggestions reach %.1f:1)\n", report.File, report.Quantizer, report.MinRatio)
+
+		table := tablewriter.NewWriter(w)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Text", "Background", "Ratio", "APCA Lc", "AA", "AA Large", "AAA", "AAA Large", "Suggestion"})
+		for _, pair := range report.Pairs {
+			table.Append([]string{
+				BackgroundColor(pair.Foreground) + pair.Foreground + Reset,
+				BackgroundColor(pair.Background) + pair.Background + Reset,
+				fmt.Sprintf("%.2f:1", pair.Ratio),
+				fmt.Sprintf("%.1f", pair.APCA),
+				passMark(pair.AANormal),
+				passMark(pair.AALarge),
+				passMark(pair.AAANormal),
+				passMark(pair.AAALarge),
+				contrastSuggestion(pair, true),
+			})
+		}
+		table.Render()
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func writeContrastJSON(w io.Writer, reports []ContrastReport) error {
+	document := make([]jsonContrastReport, 0, len(reports))
+	for _, report := range reports {
+		entry := jsonContrastReport{File: report.File, Quantizer: report.Quantizer, MinRatio: report.MinRatio, Pairs: []jsonContrastPair{}}
+		for _, pair := range report.Pairs {
+			jsonPair := jsonContrastPair{
+				Text:       pair.Foreground,
+				Background: pair.Background,
+				Ratio:      round(pair.Ratio, 2),
+				APCA:       round(pair.APCA, 1),
+				AA:         pair.AANormal,
+				AALarge:    pair.AALarge,
+				AAA:        pair.AAANormal,
+				AAALarge:   pair.AAALarge,
+			}
+			if role, hex := suggestedColor(pair); hex != "" {
+				jsonPair.Suggestion = &jsonContrastFix{Role: role, Hex: hex, Ratio: round(pair.SuggestedRatio, 2)}
+			}
+			entry.Pairs = append(entry.Pairs, jsonPair)
+		}
+		document = append(document, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+func writeContrastCSV(w io.Writer, reports []ContrastReport) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"file", "quantizer", "text", "background", "ratio", "apca_lc", "aa", "aa_large", "aaa", "aaa_large", "suggestion"})
+	for _, report := range reports {
+		for _, pair := range report.Pairs {
+			writer.Write([]string{
+				report.File,
+				report.Quantizer,
+				pair.Foreground,
+				pair.Background,
+				strconv.FormatFloat(round(pair.Ratio, 2), 'f', -1, 64),
+				strconv.FormatFloat(round(pair.APCA, 1), 'f', -1, 64),
+				strconv.FormatBool(pair.AANormal),
+				strconv.FormatBool(pair.AALarge),
+				strconv.FormatBool(pair.AAANormal),
+				strconv.FormatBool(pair.AAALarge),
+				contrastSuggestion(pair, false),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// contrastSuggestion describes the suggested adjustment of a pair, or returns "" if there is none
+func contrastSuggestion(pair palette.ContrastPair, colored bool) string {
+	role, hex := suggestedColor(pair)
+	if hex == "" {
+		return ""
+	}
+	swatch := hex
+	if colored {
+		swatch = BackgroundColor(hex) + hex + Reset
+	}
+	return fmt.Sprintf("%s → %s (%.2f:1)", role, swatch, pair.SuggestedRatio)
+}
+
+// suggestedColor returns which color of a pair the suggestion adjusts, and its new value
+func suggestedColor(pair palette.ContrastPair) (string, string) {
+	if pair.SuggestedBackground != "" {
+		return "background", pair.SuggestedBackground
+	}
+	return "text", pair.SuggestedForeground
+}
+
+func passMark(pass bool) string {
+	if pass {
+		return "pass"
+	}
+	return "fail"
+}
@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bufio"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"io"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// cssSwatch is a palette color with its generated variable name and tint/shade ramp
+type cssSwatch struct {
+	Name string
+	Hex  string
+	Ramp []palette.RampColor
+}
+
+// cssSwatches names the palette colors color-1, color-2, ... in order of frequency and builds their ramps
+func cssSwatches(colors map[string]int) ([]cssSwatch, error) {
+	var swatches []cssSwatch
+	for i, swatch := range imageprocessor.SortedSwatches(colors) {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return nil, err
+		}
+		swatches = append(swatches, cssSwatch{
+			Name: fmt.Sprintf("color-%d", i+1),
+			Hex:  swatch.Hex,
+			Ramp: palette.Ramp(c),
+		})
+	}
+	return swatches, nil
+}
+
+// writeCSS writes a palette as CSS custom properties on :root, with a 50–900 ramp per color
+func writeCSS(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	swatches, err := cssSwatches(colors)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if opts.Name != "" {
+		fmt.Fprintf(bw, "/* %s */\n", opts.Name)
+	}
+	fmt.Fprintln(bw, ":root {")
+	for _, swatch := range swatches {
+		fmt.Fprintf(bw, "  --%s: %s;\n", swatch.Name, swatch.Hex)
+		for _, step := range swatch.Ramp {
+			fmt.Fprintf(bw, "  --%s-%d: %s;\n", swatch.Name, step.Step, step.Hex)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// writeSCSS writes a palette as SCSS variables plus a $colors map of ramps
+func writeSCSS(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	swatches, err := cssSwatches(colors)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if opts.Name != "" {
+		fmt.Fprintf(bw, "// %s\n", opts.Name)
+	}
+	for _, swatch := range swatches {
+		fmt.Fprintf(bw, "$%s: %s;\n", swatch.Name, swatch.Hex)
+		for _, step := range swatch.Ramp {
+			fmt.Fprintf(bw, "$%s-%d: %s;\n", swatch.Name, step.Step, step.Hex)
+		}
+	}
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "$colors: (")
+	for _, swatch := range swatches {
+		fmt.Fprintf(bw, "  \"%s\": (\n", swatch.Name)
+		for _, step := range swatch.Ramp {
+			fmt.Fprintf(bw, "    %d: $%s-%d,\n", step.Step, swatch.Name, step.Step)
+		}
+		fmt.Fprintln(bw, "  ),")
+	}
+	fmt.Fprintln(bw, ");")
+	return bw.Flush()
+}
+
+// writeTailwind writes a palette as a tailwind.config.js extending theme.colors
+func writeTailwind(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	swatches, err := cssSwatches(colors)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if opts.Name != "" {
+		fmt.Fprintf(bw, "// %s\n", opts.Name)
+	}
+	fmt.Fprintln(bw, "module.exports = {")
+	fmt.Fprintln(bw, "  theme: {")
+	fmt.Fprintln(bw, "    extend: {")
+	fmt.Fprintln(bw, "      colors: {")
+	for _, swatch := range swatches {
+		fmt.Fprintf(bw, "        '%s': {\n", swatch.Name)
+		fmt.Fprintf(bw, "          DEFAULT: '%s',\n", swatch.Hex)
+		for _, step := range swatch.Ramp {
+			fmt.Fprintf(bw, "          %d: '%s',\n", step.Step, step.Hex)
+		}
+		fmt.Fprintln(bw, "        },")
+	}
+	fmt.Fprintln(bw, "      },")
+	fmt.Fprintln(bw, "    },")
+	fmt.Fprintln(bw, "  },")
+	fmt.Fprintln(bw, "};")
+	return bw.Flush()
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/palette"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// testRamp returns the ramp lines a format writes for a palette color, formatted by line
+func testRamp(t *testing.T, hex string, line func(step int, hex string) string) []string {
+	t.Helper()
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, step := range palette.Ramp(c) {
+		lines = append(lines, line(step.Step, step.Hex))
+	}
+	return lines
+}
+
+func TestWriteCSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "css", testPalette, PaletteOptions{Name: testPaletteName}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	want := []string{"/* " + testPaletteName + " */", ":root {"}
+	for i, hex := range testPaletteOrder {
+		name := fmt.Sprintf("color-%d", i+1)
+		want = append(want, fmt.Sprintf("  --%s: %s;", name, hex))
+		want = append(want, testRamp(t, hex, func(step int, hex string) string {
+			return fmt.Sprintf("  --%s-%d: %s;", name, step, hex)
+		})...)
+	}
+	want = append(want, "}")
+	compareLines(t, "css", lines, want)
+}
+
+func TestWriteSCSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "scss", map[string]int{"#1f77b4": 2, "#ff7f0e": 1}, PaletteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	var want []string
+	for i, hex := range []string{"#1f77b4", "#ff7f0e"} {
+		name := fmt.Sprintf("color-%d", i+1)
+		want = append(want, fmt.Sprintf("$%s: %s;", name, hex))
+		want = append(want, testRamp(t, hex, func(step int, hex string) string {
+			return fmt.Sprintf("$%s-%d: %s;", name, step, hex)
+		})...)
+	}
+	want = append(want, "", "$colors: (")
+	for i, hex := range []string{"#1f77b4", "#ff7f0e"} {
+		name := fmt.Sprintf("color-%d", i+1)
+		want = append(want, fmt.Sprintf("  \"%s\": (", name))
+		want = append(want, testRamp(t, hex, func(step int, _ string) string {
+			return fmt.Sprintf("    %d: $%s-%d,", step, name, step)
+		})...)
+		want = append(want, "  ),")
+	}
+	want = append(want, ");")
+	compareLines(t, "scss", lines, want)
+}
+
+func TestWriteTailwind(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "tailwind", map[string]int{"#1f77b4": 1}, PaletteOptions{Name: "brand"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	want := []string{"// brand", "module.exports = {", "  theme: {", "    extend: {", "      colors: {", "        'color-1': {", "          DEFAULT: '#1f77b4',"}
+	want = append(want, testRamp(t, "#1f77b4", func(step int, hex string) string {
+		return fmt.Sprintf("          %d: '%s',", step, hex)
+	})...)
+	want = append(want, "        },", "      },", "    },", "  },", "};")
+	compareLines(t, "tailwind", lines, want)
+}
+
+func TestWriteCSSInvalidColor(t *testing.T) {
+	for _, format := range []string{"css", "scss", "tailwind"} {
+		if err := WritePalette(&bytes.Buffer{}, format, map[string]int{"not a color": 1}, PaletteOptions{}); err == nil {
+			t.Errorf("%s accepted an invalid color", format)
+		}
+	}
+}
+
+func compareLines(t *testing.T, format string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s has %d lines, want %d:\n%s", format, len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s line %d = %q, want %q", format, i+1, got[i], want[i])
+		}
+	}
+}
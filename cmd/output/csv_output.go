@@ -0,0 +1,101 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// csvHeader lists the columns written by the csv and tsv formats
+var csvHeader = []string{"file", "quantizer", "rank", "hex", "r", "g", "b", "count", "percentage", "error"}
+
+// writeCSV writes one comma-separated row per file, quantizer and swatch rank
+func writeCSV(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	return writeDelimited(w, ',', results, opts)
+}
+
+// writeTSV writes one tab-separated row per file, quantizer and swatch rank
+func writeTSV(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	return writeDelimited(w, '\t', results, opts)
+}
+
+func writeDelimited(w io.Writer, delimiter rune, results []imageprocessor.ImageResult, opts Options) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if !opts.OmitHeader {
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			if err := writer.Write(csvErrorRow(result.FilePath, "", result.Err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		quantizerNames := result.Quantizers
+		if opts.IncludeFullColorExtract {
+			quantizerNames = append([]string{"ColorExtractor"}, quantizerNames...)
+		}
+
+		for _, quantizerName := range quantizerNames {
+			palette, ok := result.Results[quantizerName]
+			quantizerErr, failed := result.Errors[quantizerName]
+			if failed && !ok {
+				if err := writer.Write(csvErrorRow(result.FilePath, quantizerName, quantizerErr)); err != nil {
+					return err
+				}
+				continue
+			}
+			// A palette that couldn't be measured is still written, with the error on each row
+			errText := ""
+			if failed {
+				errText = quantizerErr.Error()
+			}
+
+			total := imageprocessor.TotalCount(palette)
+			for i, swatch := range imageprocessor.SortedSwatches(palette) {
+				var r, g, b uint8
+				if c, err := colorful.Hex(swatch.Hex); err == nil {
+					r, g, b = c.RGB255()
+				}
+				percentage := 0.0
+				if total > 0 {
+					percentage = round(float64(swatch.Count)*100/float64(total), 2)
+				}
+				err := writer.Write([]string{
+					result.FilePath,
+					quantizerName,
+					strconv.Itoa(i + 1),
+					swatch.Hex,
+					strconv.Itoa(int(r)),
+					strconv.Itoa(int(g)),
+					strconv.Itoa(int(b)),
+					strconv.Itoa(swatch.Count),
+					strconv.FormatFloat(percentage, 'f', 2, 64),
+					errText,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvErrorRow reports a failed file or quantizer, leaving the swatch columns empty
+func csvErrorRow(file, quantizer string, err error) []string {
+	row := make([]string, len(csvHeader))
+	row[0], row[1], row[len(row)-1] = file, quantizer, err.Error()
+	return row
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/imageprocessor"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	measureFailed := imageprocessor.ImageResult{
+		FilePath:   "b.png",
+		Results:    map[string]map[string]int{"KMeansQuantizer": {"#ffffff": 1}},
+		Quantizers: []string{"KMeansQuantizer"},
+		Errors:     map[string]error{"KMeansQuantizer": errors.New("measuring quality: too many colors")},
+	}
+	results := append(testResults(), measureFailed)
+
+	tests := []struct {
+		format string
+		opts   Options
+		want   string
+	}{
+		{"csv", Options{}, `file,quantizer,rank,hex,r,g,b,count,percentage,error
+a.png,KMeansQuantizer,1,#ff0000,255,0,0,3,75.00,
+a.png,KMeansQuantizer,2,#0000ff,0,0,255,1,25.00,
+a.png,Broken,,,,,,,,boom
+missing.png,,,,,,,,,no such file
+b.png,KMeansQuantizer,1,#ffffff,255,255,255,1,100.00,measuring quality: too many colors
+`},
+		{"tsv", Options{OmitHeader: true}, "a.png\tKMeansQuantizer\t1\t#ff0000\t255\t0\t0\t3\t75.00\t\n" +
+			"a.png\tKMeansQuantizer\t2\t#0000ff\t0\t0\t255\t1\t25.00\t\n" +
+			"a.png\tBroken\t\t\t\t\t\t\t\tboom\n" +
+			"missing.png\t\t\t\t\t\t\t\t\tno such file\n" +
+			"b.png\tKMeansQuantizer\t1\t#ffffff\t255\t255\t255\t1\t100.00\tmeasuring quality: too many colors\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteResults(&buf, tt.format, results, tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s output =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestWriteCSVFullColorExtract(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "csv", testResults()[:1], Options{IncludeFullColorExtract: true, OmitHeader: true}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"a.png,ColorExtractor,1,#ff0000,255,0,0,2,50.00,",
+		"a.png,ColorExtractor,2,#0000ff,0,0,255,1,25.00,",
+		"a.png,ColorExtractor,3,#fe0000,254,0,0,1,25.00,",
+		"a.png,KMeansQuantizer,1,#ff0000,255,0,0,3,75.00,",
+	}
+	if len(lines) < len(want) {
+		t.Fatalf("got %d lines, want at least %d", len(lines), len(want))
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i, lines[i], line)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteCSVWriteError(t *testing.T) {
+	for _, format := range []string{"csv", "tsv"} {
+		if err := WriteResults(failingWriter{}, format, testResults(), Options{}); err == nil || !strings.Contains(err.Error(), "disk full") {
+			t.Errorf("%s to a failing writer: error = %v, want disk full", format, err)
+		}
+	}
+}
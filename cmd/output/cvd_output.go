@@ -0,0 +1,64 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"io"
+)
+
+// CVDReport holds the color vision deficiency check of one quantizer's palette
+type CVDReport struct {
+	File       string
+	Quantizer  string
+	Colors     map[string]int // The palette checked
+	Threshold  float64        // CIEDE2000 difference below which colors are confused
+	Conditions []CVDCondition
+}
+
+// CVDCondition holds the palette as perceived with one deficiency and the pairs it confuses
+type CVDCondition struct {
+	Name      string
+	Simulated map[string]string // Original hex to perceived hex
+	Confused  []palette.ConfusedPair
+}
+
+// DisplayCVDReport prints each palette next to its simulations and lists the confused pairs
+func DisplayCVDReport(w io.Writer, reports []CVDReport) {
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s – %s\n", report.File, report.Quantizer)
+
+		swatches := imageprocessor.SortedSwatches(report.Colors)
+		fmt.Fprintf(w, "  %-14s", "original")
+		for _, swatch := range swatches {
+			fmt.Fprintf(w, " %s%s%s", BackgroundColor(swatch.Hex), swatch.Hex, Reset)
+		}
+		fmt.Fprintln(w)
+		for _, condition := range report.Conditions {
+			fmt.Fprintf(w, "  %-14s", condition.Name)
+			for _, swatch := range swatches {
+				simulated := condition.Simulated[swatch.Hex]
+				fmt.Fprintf(w, " %s%s%s", BackgroundColor(simulated), simulated, Reset)
+			}
+			fmt.Fprintln(w)
+		}
+
+		confused := 0
+		for _, condition := range report.Conditions {
+			for _, pair := range condition.Confused {
+				if confused == 0 {
+					fmt.Fprintf(w, "  %sIndistinguishable pairs (ΔE2000 < %g):%s\n", Red, report.Threshold, Reset)
+				}
+				fmt.Fprintf(w, "    %-14s %s%s%s and %s%s%s look like %s%s%s and %s%s%s (ΔE %.1f)\n", pair.Condition,
+					BackgroundColor(pair.A), pair.A, Reset, BackgroundColor(pair.B), pair.B, Reset,
+					BackgroundColor(pair.SimulatedA), pair.SimulatedA, Reset, BackgroundColor(pair.SimulatedB), pair.SimulatedB, Reset,
+					pair.DeltaE)
+				confused++
+			}
+		}
+		if confused == 0 {
+			fmt.Fprintf(w, "  %sAll pairs stay distinguishable (ΔE2000 ≥ %g).%s\n", Green, report.Threshold, Reset)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File modes for the results file
+const (
+	FileModeOverwrite = "overwrite" // Replace the file
+	FileModeAppend    = "append"    // Add to the end of the file
+	FileModeTimestamp = "timestamp" // Write a new file with a timestamp inserted before the extension
+)
+
+// FileModes lists the supported file modes
+var FileModes = []string{FileModeOverwrite, FileModeAppend, FileModeTimestamp}
+
+// extensionFormats maps results file extensions to output formats
+var extensionFormats = map[string]string{
+	".json":   "json",
+	".ndjson": "ndjson",
+	".jsonl":  "ndjson",
+	".csv":    "csv",
+	".tsv":    "tsv",
+	".tab":    "tsv",
+	".html":   "html",
+	".htm":    "html",
+}
+
+// FormatForPath infers the output format from a file's extension, or returns "" if it can't
+func FormatForPath(path string) string {
+	return extensionFormats[strings.ToLower(filepath.Ext(path))]
+}
+
+// TimestampedPath inserts a timestamp before the extension, e.g. colors.txt becomes colors-20240102-150405.txt
+func TimestampedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.Format("20060102-150405") + ext
+}
+
+// WriteFileAtomic writes a file through a temporary file in the same directory that is renamed
+// into place once complete, so an interrupted run never leaves a partial file behind. With
+// appendExisting, the current contents of the file are kept ahead of the new data.
+func WriteFileAtomic(path string, appendExisting bool, write func(w io.Writer) error) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	temp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			temp.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if appendExisting {
+		existing, err := os.Open(path)
+		if err == nil {
+			_, err = io.Copy(temp, existing)
+			existing.Close()
+			if err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := write(temp); err != nil {
+		return err
+	}
+	if err := temp.Chmod(mode); err != nil {
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// resultsFilePath resolves the path to write for the given file mode
+func resultsFilePath(filename, mode string) (string, error) {
+	switch mode {
+	case "", FileModeOverwrite, FileModeAppend:
+		return filename, nil
+	case FileModeTimestamp:
+		return TimestampedPath(filename, time.Now()), nil
+	default:
+		return "", fmt.Errorf("invalid output mode: %s. Supported modes: %s", mode, strings.Join(FileModes, ", "))
+	}
+}
+
+// hasContent reports whether the file exists and is not empty
+func hasContent(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > 0
+}
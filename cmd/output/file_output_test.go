@@ -0,0 +1,196 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readFile returns a file's contents, failing the test if it can't be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// checkNoTempFiles fails the test if WriteFileAtomic left a temporary file in dir
+func checkNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestWriteFileAtomicReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "colors.txt")
+	if err := os.WriteFile(path, []byte("old results\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteFileAtomic(path, false, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new results\n")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "new results\n" {
+		t.Errorf("contents = %q, want only the new results", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, %v, want the original 0600 kept", info.Mode().Perm(), err)
+	}
+	checkNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomicFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "colors.txt")
+	if err := os.WriteFile(path, []byte("old results\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("interrupted")
+	err := WriteFileAtomic(path, false, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("WriteFileAtomic error = %v, want %v", err, failure)
+	}
+	if got := readFile(t, path); got != "old results\n" {
+		t.Errorf("contents = %q, want the original file untouched", got)
+	}
+	checkNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomicAppends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "colors.txt")
+	write := func(text string) {
+		t.Helper()
+		err := WriteFileAtomic(path, true, func(w io.Writer) error {
+			_, err := io.WriteString(w, text)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("first\n") // The file doesn't exist yet
+	write("second\n")
+	if got := readFile(t, path); got != "first\nsecond\n" {
+		t.Errorf("contents = %q, want both runs in order", got)
+	}
+	checkNoTempFiles(t, dir)
+}
+
+func TestTimestampedPath(t *testing.T) {
+	at := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := map[string]string{
+		"colors.txt":          "colors-20240102-150405.txt",
+		"out/report.json":     "out/report-20240102-150405.json",
+		"results":             "results-20240102-150405",
+		"archive.v1/data.csv": "archive.v1/data-20240102-150405.csv",
+	}
+	for path, want := range tests {
+		if got := TimestampedPath(path, at); got != want {
+			t.Errorf("TimestampedPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]string{
+		"a.json": "json", "a.NDJSON": "ndjson", "a.jsonl": "ndjson", "a.csv": "csv",
+		"a.tsv": "tsv", "a.tab": "tsv", "a.html": "html", "a.htm": "html", "colors.txt": "", "colors": "",
+	}
+	for path, want := range tests {
+		if got := FormatForPath(path); got != want {
+			t.Errorf("FormatForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestWriteResultsToFileAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "colors.csv")
+	results := testResults()[:1]
+	for i := 0; i < 2; i++ {
+		written, err := WriteResultsToFile(path, FileModeAppend, results, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != path {
+			t.Errorf("wrote %s, want %s", written, path)
+		}
+	}
+
+	var once bytes.Buffer
+	if err := WriteResults(&once, "csv", results, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	header, rows, _ := strings.Cut(once.String(), "\n")
+	if want := header + "\n" + rows + rows; readFile(t, path) != want {
+		t.Errorf("contents = %q, want one header followed by the rows of both runs %q", readFile(t, path), want)
+	}
+}
+
+func TestWriteResultsToFileRejectsAppendingDocuments(t *testing.T) {
+	for _, filename := range []string{"colors.json", "colors.html"} {
+		path := filepath.Join(t.TempDir(), filename)
+		if err := os.WriteFile(path, []byte("existing"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := WriteResultsToFile(path, FileModeAppend, testResults(), Options{})
+		if err == nil || !strings.Contains(err.Error(), "can't append") {
+			t.Errorf("%s: error = %v, want appending rejected", filename, err)
+		}
+		if got := readFile(t, path); got != "existing" {
+			t.Errorf("%s: contents = %q, want the file untouched", filename, got)
+		}
+	}
+}
+
+func TestWriteResultsToFileTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "colors.json")
+	if err := os.WriteFile(path, []byte("existing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := WriteResultsToFile(path, FileModeTimestamp, testResults(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Base(written)
+	if filepath.Dir(written) != dir || !strings.HasPrefix(name, "colors-") || !strings.HasSuffix(name, ".json") || len(name) != len("colors-20060102-150405.json") {
+		t.Errorf("wrote %s, want a timestamped colors-*.json next to %s", written, path)
+	}
+	if got := readFile(t, path); got != "existing" {
+		t.Errorf("contents = %q, want the existing file untouched", got)
+	}
+	if !strings.HasPrefix(readFile(t, written), "{") {
+		t.Errorf("%s doesn't hold the JSON results", written)
+	}
+}
+
+func TestWriteResultsToFileInvalidMode(t *testing.T) {
+	_, err := WriteResultsToFile(filepath.Join(t.TempDir(), "colors.txt"), "rotate", testResults(), Options{})
+	if err == nil || !strings.Contains(err.Error(), "invalid output mode") {
+		t.Errorf("error = %v, want an invalid output mode", err)
+	}
+}
@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bufio"
+	"colorsage/imageprocessor"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// GPLPalette is a GIMP palette as used by GIMP, Inkscape and Krita
+type GPLPalette struct {
+	Name     string
+	Columns  int
+	Swatches []GPLSwatch
+}
+
+// GPLSwatch is a single named color of a GIMP palette
+type GPLSwatch struct {
+	Hex      string
+	Name     string
+	Coverage float64 // Share of the image in percent, written after the name as "(12.34%)"; 0 if unknown
+}
+
+// gplCoverageScale converts coverage percentages with two decimals into whole counts
+const gplCoverageScale = 100
+
+// Colors converts the palette into a color map. When every swatch has a coverage, the counts keep
+// its proportions; otherwise each swatch counts once, so repeated colors count more.
+func (p GPLPalette) Colors() map[string]int {
+	colors := make(map[string]int, len(p.Swatches))
+	if p.hasCoverage() {
+		for _, swatch := range p.Swatches {
+			// Colors too rare to show up at two decimals still count
+			colors[swatch.Hex] += max(1, int(math.Round(swatch.Coverage*gplCoverageScale)))
+		}
+		return colors
+	}
+	for _, swatch := range p.Swatches {
+		colors[swatch.Hex]++
+	}
+	return colors
+}
+
+// hasCoverage reports whether every swatch of a non-empty palette has a coverage
+func (p GPLPalette) hasCoverage() bool {
+	for _, swatch := range p.Swatches {
+		if swatch.Coverage <= 0 {
+			return false
+		}
+	}
+	return len(p.Swatches) > 0
+}
+
+// writeGPL writes a palette in GIMP Palette (.gpl) format, most frequent color first
+func writeGPL(w io.Writer, palette map[string]int, opts PaletteOptions) error {
+	total := imageprocessor.TotalCount(palette)
+	gpl := GPLPalette{Name: opts.Name, Columns: opts.Columns}
+	for _, swatch := range imageprocessor.SortedSwatches(palette) {
+		entry := GPLSwatch{Hex: swatch.Hex, Name: swatch.Hex}
+		if total > 0 {
+			entry.Coverage = float64(swatch.Count) * 100 / float64(total)
+		}
+		gpl.Swatches = append(gpl.Swatches, entry)
+	}
+	return WriteGPL(w, gpl)
+}
+
+// WriteGPL writes a GIMP palette. Columns defaults to the number of swatches, capped at 16.
+func WriteGPL(w io.Writer, palette GPLPalette) error {
+	columns := palette.Columns
+	if columns <= 0 {
+		columns = len(palette.Swatches)
+		if columns > 16 {
+			columns = 16
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "GIMP Palette")
+	fmt.Fprintf(bw, "Name: %s\n", strings.ReplaceAll(palette.Name, "\n", " "))
+	fmt.Fprintf(bw, "Columns: %d\n", columns)
+	fmt.Fprintln(bw, "#")
+	for _, swatch := range palette.Swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return fmt.Errorf("invalid color %q in palette", swatch.Hex)
+		}
+		r, g, b := c.RGB255()
+		name := swatch.Name
+		if swatch.Coverage > 0 {
+			name = strings.TrimSpace(fmt.Sprintf("%s (%.2f%%)", name, swatch.Coverage))
+		}
+		fmt.Fprintf(bw, "%3d %3d %3d\t%s\n", r, g, b, name)
+	}
+	return bw.Flush()
+}
+
+// ReadGPL parses a GIMP palette, taking a trailing "(12.34%)" in a swatch name as its coverage
+func ReadGPL(r io.Reader) (GPLPalette, error) {
+	var palette GPLPalette
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			if !strings.HasPrefix(text, "GIMP Palette") {
+				return GPLPalette{}, fmt.Errorf("not a GIMP palette: missing \"GIMP Palette\" header")
+			}
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if value, ok := strings.CutPrefix(text, "Name:"); ok {
+			palette.Name = strings.TrimSpace(value)
+			continue
+		}
+		if value, ok := strings.CutPrefix(text, "Columns:"); ok {
+			columns, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return GPLPalette{}, fmt.Errorf("line %d: invalid column count %q", line, value)
+			}
+			palette.Columns = columns
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 3 {
+			return GPLPalette{}, fmt.Errorf("line %d: expected \"R G B [name]\"", line)
+		}
+		var rgb [3]uint8
+		for i := range rgb {
+			v, err := strconv.Atoi(fields[i])
+			if err != nil || v < 0 || v > 255 {
+				return GPLPalette{}, fmt.Errorf("line %d: invalid color component %q", line, fields[i])
+			}
+			rgb[i] = uint8(v)
+		}
+		hex := fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+		name, coverage := splitGPLCoverage(strings.Join(fields[3:], " "))
+		palette.Swatches = append(palette.Swatches, GPLSwatch{Hex: hex, Name: name, Coverage: coverage})
+	}
+	if err := scanner.Err(); err != nil {
+		return GPLPalette{}, err
+	}
+	if line == 0 {
+		return GPLPalette{}, fmt.Errorf("not a GIMP palette: empty file")
+	}
+	return palette, nil
+}
+
+// splitGPLCoverage separates a trailing "(12.34%)" coverage from a swatch name, returning 0 if there is none
+func splitGPLCoverage(name string) (string, float64) {
+	rest, ok := strings.CutSuffix(name, "%)")
+	if !ok {
+		return name, 0
+	}
+	open := strings.LastIndex(rest, "(")
+	if open < 0 {
+		return name, 0
+	}
+	coverage, err := strconv.ParseFloat(rest[open+1:], 64)
+	if err != nil || coverage <= 0 || coverage > 100 {
+		return name, 0
+	}
+	return strings.TrimSpace(rest[:open]), coverage
+}
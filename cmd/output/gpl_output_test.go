@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteGPL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "gpl", testPalette, PaletteOptions{Name: testPaletteName, Columns: 3}); err != nil {
+		t.Fatal(err)
+	}
+	want := "GIMP Palette\n" +
+		"Name: " + testPaletteName + "\n" +
+		"Columns: 3\n" +
+		"#\n" +
+		"255   0   0\t#ff0000 (45.45%)\n" +
+		"  0 255 128\t#00ff80 (30.30%)\n" +
+		" 32  32  32\t#202020 (15.15%)\n" +
+		"240 230 140\t#f0e68c (7.58%)\n" +
+		"  0   0 255\t#0000ff (1.52%)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("GPL output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGPLRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "gpl", testPalette, PaletteOptions{Name: testPaletteName}); err != nil {
+		t.Fatal(err)
+	}
+	gpl, err := ReadGPL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gpl.Name != testPaletteName || gpl.Columns != len(testPalette) {
+		t.Errorf("name, columns = %q, %d", gpl.Name, gpl.Columns)
+	}
+	if len(gpl.Swatches) != len(testPaletteOrder) {
+		t.Fatalf("read %d swatches, want %d", len(gpl.Swatches), len(testPaletteOrder))
+	}
+	for i, hex := range testPaletteOrder {
+		swatch := gpl.Swatches[i]
+		want := float64(testPalette[hex]) * 100 / 66
+		if swatch.Hex != hex || swatch.Name != hex || math.Abs(swatch.Coverage-want) > 0.005 {
+			t.Errorf("swatch %d = %+v, want %s with coverage %.2f", i, swatch, hex, want)
+		}
+	}
+
+	// The counts come back in the written proportions
+	colors := gpl.Colors()
+	total := 0
+	for _, count := range colors {
+		total += count
+	}
+	for hex, count := range testPalette {
+		got, want := float64(colors[hex])/float64(total), float64(count)/66
+		if math.Abs(got-want) > 0.0001 {
+			t.Errorf("%s share = %.4f, want %.4f", hex, got, want)
+		}
+	}
+}
+
+func TestReadGPL(t *testing.T) {
+	input := "GIMP Palette\n" +
+		"Name: Brand\n" +
+		"Columns: 2\n" +
+		"# A comment\n" +
+		"\n" +
+		"255 0 0\tRed (primary)\n" +
+		"  0 0 255 Deep Blue (25%)\n" +
+		"0 255 0\n"
+	gpl, err := ReadGPL(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GPLPalette{
+		Name:    "Brand",
+		Columns: 2,
+		Swatches: []GPLSwatch{
+			{Hex: "#ff0000", Name: "Red (primary)"},
+			{Hex: "#0000ff", Name: "Deep Blue", Coverage: 25},
+			{Hex: "#00ff00"},
+		},
+	}
+	if !reflect.DeepEqual(gpl, want) {
+		t.Errorf("ReadGPL = %+v, want %+v", gpl, want)
+	}
+
+	// Without coverage on every swatch, each swatch counts once
+	if colors := gpl.Colors(); !reflect.DeepEqual(colors, map[string]int{"#ff0000": 1, "#0000ff": 1, "#00ff00": 1}) {
+		t.Errorf("Colors = %v", colors)
+	}
+}
+
+func TestReadGPLErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                                  "empty file",
+		"JASC-PAL\n":                        "missing \"GIMP Palette\" header",
+		"GIMP Palette\nColumns: x\n":        "invalid column count",
+		"GIMP Palette\n255 0\n":             "expected \"R G B [name]\"",
+		"GIMP Palette\n256 0 0 Too red\n":   "invalid color component \"256\"",
+		"GIMP Palette\nred 0 0 Not a num\n": "invalid color component \"red\"",
+	}
+	for input, want := range tests {
+		if _, err := ReadGPL(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadGPL(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestReadPaletteColors(t *testing.T) {
+	dir := t.TempDir()
+	gplPath := filepath.Join(dir, "palette.gpl")
+	file, err := os.Create(gplPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WritePalette(file, "gpl", map[string]int{"#ff0000": 3, "#0000ff": 1}, PaletteOptions{})
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors, err := ReadPaletteColors(gplPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"#ff0000": 7500, "#0000ff": 2500}; !reflect.DeepEqual(colors, want) {
+		t.Errorf("GPL colors = %v, want %v", colors, want)
+	}
+
+	listPath := filepath.Join(dir, "palette.txt")
+	if err := os.WriteFile(listPath, []byte("#ff0000\n#0000ff Blue\n#ff0000\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	colors, err = ReadPaletteColors(listPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"#ff0000": 2, "#0000ff": 1}; !reflect.DeepEqual(colors, want) {
+		t.Errorf("hex list colors = %v, want %v", colors, want)
+	}
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// HarmonyRow is one named set of colors of a harmony kit, e.g. a scheme or a ramp
+type HarmonyRow struct {
+	Name   string
+	Colors []string
+}
+
+// DisplayHarmony prints the base color and its schemes and ramps as a table of colored swatches
+func DisplayHarmony(w io.Writer, base string, rows []HarmonyRow) {
+	fmt.Fprintf(w, "Base color: %s%s%s\n", BackgroundColor(base), base, Reset)
+
+	table := tablewriter.NewWriter(w)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Harmony", "Colors"})
+	for _, row := range rows {
+		swatches := make([]string, len(row.Colors))
+		for i, hex := range row.Colors {
+			swatches[i] = BackgroundColor(hex) + hex + Reset
+		}
+		table.Append([]string{row.Name, strings.Join(swatches, " ")})
+	}
+	table.Render()
+}
+
+// HarmonyPalette turns an ordered list of colors into a palette that keeps the order when drawn
+func HarmonyPalette(colors []string) map[string]int {
+	palette := make(map[string]int, len(colors))
+	for i, hex := range colors {
+		if _, ok := palette[hex]; !ok {
+			palette[hex] = len(colors) - i
+		}
+	}
+	return palette
+}
@@ -2,8 +2,11 @@
 package output
 
 import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/lucasb-eyer/go-colorful"
 	"golang.org/x/term"
@@ -38,23 +41,14 @@ func BackgroundColor(hex string) string {
 
 // SummarizeColors calculates summary statistics for a color palette
 func SummarizeColors(colorMap map[string]int) ColorSummary {
-	summary := ColorSummary{
-		TotalColors:        len(colorMap),
-		MostFrequentCount:  0,
-		LeastFrequentCount: int(^uint(0) >> 1), // Initialize with max int
+	summary := ColorSummary{TotalColors: len(colorMap)}
+	// Ties are broken by hex, so the summary doesn't change from run to run
+	swatches := imageprocessor.SortedSwatches(colorMap)
+	if len(swatches) > 0 {
+		most, least := swatches[0], swatches[len(swatches)-1]
+		summary.MostFrequentColor, summary.MostFrequentCount = most.Hex, most.Count
+		summary.LeastFrequentColor, summary.LeastFrequentCount = least.Hex, least.Count
 	}
-
-	for hex, count := range colorMap {
-		if count > summary.MostFrequentCount {
-			summary.MostFrequentColor = hex
-			summary.MostFrequentCount = count
-		}
-		if count < summary.LeastFrequentCount {
-			summary.LeastFrequentColor = hex
-			summary.LeastFrequentCount = count
-		}
-	}
-
 	return summary
 }
 
@@ -66,3 +60,38 @@ type ColorSummary struct {
 	LeastFrequentColor string
 	LeastFrequentCount int
 }
+
+// swatchName returns the color name of a swatch, or "" without a namer
+func swatchName(namer *palette.ColorNamer, hex string) string {
+	if namer == nil {
+		return ""
+	}
+	name, err := namer.Name(hex)
+	if err != nil {
+		return ""
+	}
+	return name.Name
+}
+
+// withName appends the color name of a swatch in parentheses, if there is one
+func withName(namer *palette.ColorNamer, hex string) string {
+	if name := swatchName(namer, hex); name != "" {
+		return hex + " (" + name + ")"
+	}
+	return hex
+}
+
+// FormatMetrics describes the quality metrics of a quantizer on one line
+func FormatMetrics(m imageprocessor.QualityMetrics) string {
+	return fmt.Sprintf("MSE %.2f, PSNR %.2f dB, SSIM %.4f, ΔE2000 mean %.2f / p95 %.2f", m.MSE, m.PSNR, m.SSIM, m.MeanDeltaE, m.P95DeltaE)
+}
+
+// formatRanking lists the measured quantizers of a result from best to worst, or returns "" if
+// there is nothing to compare
+func formatRanking(result imageprocessor.ImageResult) string {
+	ranking := imageprocessor.RankQuantizers(result)
+	if len(ranking) < 2 {
+		return ""
+	}
+	return strings.Join(ranking, " > ")
+}
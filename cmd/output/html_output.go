@@ -0,0 +1,161 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/imageprocessor"
+	"encoding/base64"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"io"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// thumbnailWidth is the maximum width of the thumbnails embedded in HTML reports
+const thumbnailWidth = 240
+
+// htmlReport is the data behind the HTML report template
+type htmlReport struct {
+	Images []htmlImage
+}
+
+type htmlImage struct {
+	File       string
+	Error      string
+	Thumbnail  template.URL
+	Summary    *JSONSummary
+	Quantizers []JSONQuantizer
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>colorsage report</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; background: #fafafa; }
+  section { background: #fff; border: 1px solid #ddd; border-radius: 8px; padding: 1rem 1.5rem; margin-bottom: 2rem; }
+  h2 { font-size: 1.1rem; word-break: break-all; }
+  .overview { display: flex; gap: 1.5rem; align-items: flex-start; }
+  .overview img { border-radius: 4px; }
+  .summary td { padding: 0.1rem 0.8rem 0.1rem 0; }
+  .quantizers { display: flex; gap: 1.5rem; flex-wrap: wrap; margin-top: 1rem; }
+  .quantizer { flex: 1 1 220px; }
+  .quantizer h3 { font-size: 0.95rem; margin: 0 0 0.5rem; }
+  .quantizer .timing { color: #888; font-weight: normal; }
+  .swatch { display: flex; align-items: center; gap: 0.5rem; margin: 0.25rem 0; }
+  .chip { width: 1.6rem; height: 1.6rem; border-radius: 4px; border: 1px solid rgba(0,0,0,0.15); flex: none; }
+  .hex { font-family: monospace; border: none; background: none; cursor: pointer; padding: 0; font-size: 0.9rem; }
+  .hex:hover { text-decoration: underline; }
+  .name { font-size: 0.8rem; color: #555; }
+  .bar { flex: 1; height: 0.6rem; background: #eee; border-radius: 3px; overflow: hidden; }
+  .bar span { display: block; height: 100%; }
+  .pct { width: 3.5rem; text-align: right; font-size: 0.8rem; color: #555; }
+  .metrics { font-size: 0.8rem; color: #555; margin: 0 0 0.5rem; }
+  .error { color: #b00020; }
+  #toast { position: fixed; bottom: 1rem; right: 1rem; background: #222; color: #fff; padding: 0.4rem 0.8rem; border-radius: 4px; opacity: 0; transition: opacity 0.2s; }
+</style>
+</head>
+<body>
+<h1>colorsage report</h1>
+{{range .Images}}
+<section>
+  <h2>{{.File}}</h2>
+  {{if .Error}}<p class="error">Error: {{.Error}}</p>{{else}}
+  <div class="overview">
+    {{if .Thumbnail}}<img src="{{.Thumbnail}}" alt="{{.File}}">{{end}}
+    {{with .Summary}}
+    <table class="summary">
+      <tr><td>Total colors</td><td>{{.TotalColors}}</td></tr>
+      <tr><td>Most frequent</td><td><button class="hex" data-hex="{{.MostFrequent.Hex}}">{{.MostFrequent.Hex}}</button> ({{.MostFrequent.Count}})</td></tr>
+      <tr><td>Least frequent</td><td><button class="hex" data-hex="{{.LeastFrequent.Hex}}">{{.LeastFrequent.Hex}}</button> ({{.LeastFrequent.Count}})</td></tr>
+    </table>
+    {{end}}
+  </div>
+  <div class="quantizers">
+    {{range .Quantizers}}
+    <div class="quantizer">
+      <h3>{{.Name}} <span class="timing">{{printf "%.1f" .DurationMs}} ms</span></h3>
+      {{if .Error}}<p class="error">Error: {{.Error}}</p>{{end}}
+      {{with .Metrics}}<p class="metrics">#{{.Rank}} · ΔE2000 {{printf "%.2f" .MeanDeltaE}} (p95 {{printf "%.2f" .P95DeltaE}}) · SSIM {{printf "%.3f" .SSIM}} · PSNR {{printf "%.1f" .PSNR}} dB</p>{{end}}
+      {{range .Swatches}}
+      <div class="swatch">
+        <span class="chip" style="background: {{.Hex}}"></span>
+        <button class="hex" data-hex="{{.Hex}}" title="Copy {{.Hex}}">{{.Hex}}</button>
+        {{if .Name}}<span class="name">{{.Name}}</span>{{end}}
+        <span class="bar"><span style="width: {{.Percentage}}%; background: {{.Hex}}"></span></span>
+        <span class="pct">{{printf "%.1f" .Percentage}}%</span>
+      </div>
+      {{end}}
+    </div>
+    {{end}}
+  </div>
+  {{end}}
+</section>
+{{end}}
+<div id="toast"></div>
+<script>
+  document.addEventListener("click", function (event) {
+    var hex = event.target.getAttribute && event.target.getAttribute("data-hex");
+    if (!hex) return;
+    var done = function () {
+      var toast = document.getElementById("toast");
+      toast.textContent = "Copied " + hex;
+      toast.style.opacity = 1;
+      setTimeout(function () { toast.style.opacity = 0; }, 1200);
+    };
+    if (navigator.clipboard && navigator.clipboard.writeText) {
+      navigator.clipboard.writeText(hex).then(done);
+    } else {
+      var input = document.createElement("textarea");
+      input.value = hex;
+      document.body.appendChild(input);
+      input.select();
+      document.execCommand("copy");
+      document.body.removeChild(input);
+      done();
+    }
+  });
+</script>
+</body>
+</html>
+`))
+
+// writeHTML writes a self-contained HTML report with embedded thumbnails, the palettes of every
+// quantizer side by side with coverage bars, and summary statistics.
+func writeHTML(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	report := htmlReport{}
+	for _, result := range results {
+		data := NewJSONImage(result, opts)
+		entry := htmlImage{
+			File:       data.File,
+			Error:      data.Error,
+			Summary:    data.Summary,
+			Quantizers: data.Quantizers,
+		}
+		if result.Image != nil {
+			entry.Thumbnail = thumbnailDataURL(result.Image)
+		}
+		report.Images = append(report.Images, entry)
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+// thumbnailDataURL returns a base64 JPEG data URL of a downscaled copy of the image, or "" if it can't be encoded
+func thumbnailDataURL(src image.Image) template.URL {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailWidth {
+		height = height * thumbnailWidth / width
+		width = thumbnailWidth
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, width, max(height, 1)))
+	xdraw.CatmullRom.Scale(thumb, thumb.Bounds(), src, bounds, xdraw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+		return ""
+	}
+	return template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/imageprocessor"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "html", testResults(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	report := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h2>a.png</h2>",
+		`<span class="timing">1.5 ms</span>`,
+		`<button class="hex" data-hex="#ff0000" title="Copy #ff0000">#ff0000</button>`,
+		`<span style="width: 75%; background: #ff0000"></span>`,
+		`<span class="pct">75.0%</span>`,
+		`<p class="error">Error: boom</p>`,
+		"<h2>missing.png</h2>",
+		`<p class="error">Error: no such file</p>`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+	if strings.Contains(report, "<img") {
+		t.Error("report has a thumbnail for results without an image")
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	results := []imageprocessor.ImageResult{
+		{
+			FilePath:   `<script>alert("x")</script>.png`,
+			Results:    map[string]map[string]int{"Plugin": {"#ff0000": 1}},
+			Quantizers: []string{"Plugin", "<b>Broken</b>"},
+			Errors:     map[string]error{"<b>Broken</b>": errors.New(`exit "1" & <stderr>`)},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "html", results, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	report := buf.String()
+
+	for _, unescaped := range []string{"<script>alert", "<b>Broken</b>", "<stderr>"} {
+		if strings.Contains(report, unescaped) {
+			t.Errorf("report contains unescaped %q", unescaped)
+		}
+	}
+	for _, want := range []string{
+		"<h2>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;.png</h2>",
+		"&lt;b&gt;Broken&lt;/b&gt;",
+		"Error: exit &#34;1&#34; &amp; &lt;stderr&gt;",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing escaped %q", want)
+		}
+	}
+}
+
+func TestWriteHTMLThumbnail(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 480, 120))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	results := []imageprocessor.ImageResult{
+		{FilePath: "wide.png", Image: img, Results: map[string]map[string]int{"KMeansQuantizer": {"#ffffff": 1}}, Quantizers: []string{"KMeansQuantizer"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "html", results, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<img src="data:image/jpeg;base64,`) {
+		t.Error("report has no embedded JPEG thumbnail")
+	}
+
+	encoded, ok := strings.CutPrefix(string(thumbnailDataURL(img)), "data:image/jpeg;base64,")
+	if !ok {
+		t.Fatal("thumbnail is not a JPEG data URL")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != thumbnailWidth || config.Height != thumbnailWidth/4 {
+		t.Errorf("thumbnail is %dx%d, want %dx%d", config.Width, config.Height, thumbnailWidth, thumbnailWidth/4)
+	}
+}
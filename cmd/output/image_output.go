@@ -2,55 +2,269 @@
 package output
 
 import (
-	"colorsage/config"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
-	"os"
-	"path/filepath"
+	"io"
+	"math"
 	"strings"
 
 	"github.com/lucasb-eyer/go-colorful"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
 )
 
 // GeneratePaletteImage creates a PNG image representing the given colors and saves it to the specified file path.
 func GeneratePaletteImage(colors map[string]int, filePath string) error {
-	const blockWidth, blockHeight = 50, 50
-	imgWidth := len(colors) * blockWidth
-	imgHeight := blockHeight
+	return WritePaletteFile(filePath, "png", colors, PaletteOptions{})
+}
 
-	img := image.NewRGBA(image.Rect(0, 0, imgWidth, imgHeight))
-	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+// Palette image layouts
+const (
+	LayoutHorizontal = "horizontal" // A single row of swatches
+	LayoutVertical   = "vertical"   // A single column of swatches, wide enough for labels
+	LayoutGrid       = "grid"       // Rows of equal-sized swatches
+)
+
+// Layouts lists the supported palette image layouts
+var Layouts = []string{LayoutHorizontal, LayoutVertical, LayoutGrid}
+
+// defaultBlockSize is the edge length of a swatch block in pixels
+const defaultBlockSize = 50
+
+// labelFace is the font used for swatch labels
+var labelFace font.Face = basicfont.Face7x13
+
+// writePalettePNG draws the given colors according to the layout options and encodes it as PNG
+func writePalettePNG(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	img, err := RenderPalette(colors, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// RenderPalette draws the given colors as swatch blocks, honouring the layout, order, proportional
+// widths, labels and block size of the options.
+func RenderPalette(colors map[string]int, opts PaletteOptions) (*image.RGBA, error) {
+	swatches, err := palette.SortSwatches(colors, opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	canvas, blocks, err := layoutSwatches(swatches, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	img := newCanvas(canvas.Dx(), canvas.Dy())
+	total := imageprocessor.TotalCount(colors)
+	for i, swatch := range swatches {
+		if err := drawSwatch(img, blocks[i], swatch, total, opts); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// layoutSwatches places already ordered swatches according to the layout options, returning the
+// canvas bounds and one block per swatch. Raster and vector palettes share it so they match.
+func layoutSwatches(swatches []imageprocessor.Swatch, opts PaletteOptions) (image.Rectangle, []image.Rectangle, error) {
+	if len(swatches) == 0 {
+		return image.Rectangle{}, nil, fmt.Errorf("palette has no colors")
+	}
+
+	blockSize := opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+	n := len(swatches)
+
+	switch opts.Layout {
+	case "", LayoutHorizontal:
+		canvas := image.Rect(0, 0, n*blockSize, blockSize)
+		return canvas, stripBlocks(canvas, swatches, opts.Proportional, false), nil
+	case LayoutVertical:
+		// Wide enough for a label with the coverage percentage
+		canvas := image.Rect(0, 0, max(4*blockSize, 14*labelFace.Metrics().Height.Ceil()), n*blockSize)
+		return canvas, stripBlocks(canvas, swatches, opts.Proportional, true), nil
+	case LayoutGrid:
+		columns := opts.Columns
+		if columns <= 0 {
+			columns = int(math.Ceil(math.Sqrt(float64(n))))
+		}
+		rows := (n + columns - 1) / columns
+		blocks := make([]image.Rectangle, n)
+		for i := range swatches {
+			x, y := (i%columns)*blockSize, (i/columns)*blockSize
+			blocks[i] = image.Rect(x, y, x+blockSize, y+blockSize)
+		}
+		return image.Rect(0, 0, columns*blockSize, rows*blockSize), blocks, nil
+	default:
+		return image.Rectangle{}, nil, fmt.Errorf("unknown palette layout %q. Supported layouts: %s", opts.Layout, strings.Join(Layouts, ", "))
+	}
+}
+
+// WritePaletteCard draws a thumbnail of the source image with a horizontal palette strip below it
+func WritePaletteCard(w io.Writer, source image.Image, colors map[string]int, opts PaletteOptions) error {
+	swatches, err := palette.SortSwatches(colors, opts.Sort)
+	if err != nil {
+		return err
+	}
+
+	blockSize := opts.BlockSize
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+
+	// Scale the source to the card width, keeping its aspect ratio
+	const cardWidth = 480
+	bounds := source.Bounds()
+	thumbHeight := cardWidth
+	if bounds.Dx() > 0 {
+		thumbHeight = int(math.Round(float64(bounds.Dy()) * cardWidth / float64(bounds.Dx())))
+	}
 
-	i := 0
-	for hex := range colors {
-		c, err := colorful.Hex(hex)
-		if err != nil {
+	img := newCanvas(cardWidth, thumbHeight+blockSize)
+	xdraw.CatmullRom.Scale(img, image.Rect(0, 0, cardWidth, thumbHeight), source, bounds, xdraw.Src, nil)
+	total := imageprocessor.TotalCount(colors)
+	strip := image.Rect(0, thumbHeight, cardWidth, thumbHeight+blockSize)
+	for i, block := range stripBlocks(strip, swatches, opts.Proportional, false) {
+		if err := drawSwatch(img, block, swatches[i], total, opts); err != nil {
 			return err
 		}
-		draw.Draw(img, image.Rect(i*blockWidth, 0, (i+1)*blockWidth, blockHeight), &image.Uniform{C: color.RGBA{uint8(c.R * 255), uint8(c.G * 255), uint8(c.B * 255), 255}}, image.Point{}, draw.Src)
-		i++
 	}
 
-	file, err := os.Create(filePath)
+	return png.Encode(w, img)
+}
+
+// GeneratePaletteCard writes a palette card for the source image to filePath
+func GeneratePaletteCard(source image.Image, colors map[string]int, filePath string, opts PaletteOptions) error {
+	return WriteFileAtomic(filePath, false, func(w io.Writer) error {
+		return WritePaletteCard(w, source, colors, opts)
+	})
+}
+
+func newCanvas(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	return img
+}
+
+// stripBlocks divides rect among the swatches side by side, or stacked when vertical. When
+// proportional, each swatch's share of the strip follows its share of the counts.
+func stripBlocks(rect image.Rectangle, swatches []imageprocessor.Swatch, proportional, vertical bool) []image.Rectangle {
+	total := 0
+	for _, swatch := range swatches {
+		total += swatch.Count
+	}
+
+	length := rect.Dx()
+	if vertical {
+		length = rect.Dy()
+	}
+
+	// Round where each block ends rather than each size, so rounding never accumulates past the strip
+	blocks := make([]image.Rectangle, len(swatches))
+	offset, cumulative := 0, 0
+	for i, swatch := range swatches {
+		cumulative += swatch.Count
+		end := length * (i + 1) / len(swatches)
+		if proportional && total > 0 {
+			end = int(math.Round(float64(length) * float64(cumulative) / float64(total)))
+		}
+		size := end - offset
+
+		blocks[i] = image.Rect(rect.Min.X+offset, rect.Min.Y, rect.Min.X+offset+size, rect.Max.Y)
+		if vertical {
+			blocks[i] = image.Rect(rect.Min.X, rect.Min.Y+offset, rect.Max.X, rect.Min.Y+offset+size)
+		}
+		offset += size
+	}
+	return blocks
+}
+
+// swatchLabel picks the label for a swatch block: the color name, if given, or else the hex, with
+// its coverage if that fits, else bare if that fits, else nothing. measure returns the rendered width
+// of a string.
+func swatchLabel(swatch imageprocessor.Swatch, name string, total int, block image.Rectangle, lineHeight int, measure func(string) int) string {
+	if block.Dy() < lineHeight {
+		return ""
+	}
+	candidates := []string{swatch.Hex}
+	if name != "" {
+		candidates = []string{name, swatch.Hex}
+	}
+	const padding = 4
+	for _, text := range candidates {
+		if total > 0 {
+			withPercentage := fmt.Sprintf("%s %.1f%%", text, float64(swatch.Count)*100/float64(total))
+			if measure(withPercentage)+padding <= block.Dx() {
+				return withPercentage
+			}
+		}
+		if measure(text) <= block.Dx() {
+			return text
+		}
+	}
+	return ""
+}
+
+// prefersWhiteText reports whether white text contrasts more with c than black text
+func prefersWhiteText(c colorful.Color) bool {
+	return palette.ContrastRatio(c, colorful.Color{R: 1, G: 1, B: 1}) > palette.ContrastRatio(c, colorful.Color{})
+}
+
+// drawSwatch fills a block with the swatch color and, if requested and it fits, a centered label
+// in black or white, whichever contrasts more with the swatch.
+func drawSwatch(img *image.RGBA, block image.Rectangle, swatch imageprocessor.Swatch, total int, opts PaletteOptions) error {
+	c, err := colorful.Hex(swatch.Hex)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	r, g, b := c.RGB255()
+	draw.Draw(img, block, &image.Uniform{C: color.RGBA{r, g, b, 255}}, image.Point{}, draw.Src)
+
+	if !opts.Labels {
+		return nil
+	}
+
+	metrics := labelFace.Metrics()
+	textHeight := metrics.Ascent.Ceil() + metrics.Descent.Ceil()
+	text := swatchLabel(swatch, swatchName(opts.Namer, swatch.Hex), total, block, textHeight, func(text string) int {
+		return font.MeasureString(labelFace, text).Ceil()
+	})
+	if text == "" {
+		return nil
+	}
 
-	return png.Encode(file, img)
+	labelColor := color.Color(color.Black)
+	if prefersWhiteText(c) {
+		labelColor = color.White
+	}
+	drawCenteredText(img, block, text, labelColor)
+	return nil
 }
 
-// GeneratePaletteFilename constructs the filename for the palette image based on the provided file path and quantizer name.
-func GeneratePaletteFilename(filePath, quantizerName string) string {
-	baseName := filepath.Base(filePath)
-	ext := filepath.Ext(baseName)
-	nameWithoutExt := strings.TrimSuffix(baseName, ext)
+// drawCenteredText draws a line of text in the middle of a block
+func drawCenteredText(img *image.RGBA, block image.Rectangle, text string, c color.Color) {
+	metrics := labelFace.Metrics()
+	textHeight := metrics.Ascent.Ceil() + metrics.Descent.Ceil()
+	textWidth := font.MeasureString(labelFace, text).Ceil()
 
-	if config.GeneratePaletteImagesInCurrentDir {
-		return fmt.Sprintf("./%s_%s_palette.png", nameWithoutExt, quantizerName)
+	drawer := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: labelFace,
+		Dot: fixed.P(
+			block.Min.X+(block.Dx()-textWidth)/2,
+			block.Min.Y+(block.Dy()-textHeight)/2+metrics.Ascent.Ceil(),
+		),
 	}
-	return fmt.Sprintf("%s_%s_palette.png", nameWithoutExt, quantizerName)
+	drawer.DrawString(text)
 }
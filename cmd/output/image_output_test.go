@@ -0,0 +1,66 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"image"
+	"testing"
+)
+
+func TestStripBlocks(t *testing.T) {
+	swatches := func(counts ...int) []imageprocessor.Swatch {
+		result := make([]imageprocessor.Swatch, len(counts))
+		for i, count := range counts {
+			result[i] = imageprocessor.Swatch{Hex: "#000000", Count: count}
+		}
+		return result
+	}
+	manyHalves := make([]int, 20)
+	for i := range manyHalves {
+		manyHalves[i] = 1
+	}
+
+	tests := []struct {
+		name         string
+		rect         image.Rectangle
+		swatches     []imageprocessor.Swatch
+		proportional bool
+		vertical     bool
+		wantSizes    []int
+	}{
+		{name: "equal", rect: image.Rect(0, 0, 100, 10), swatches: swatches(5, 1, 1), wantSizes: []int{33, 33, 34}},
+		{name: "proportional", rect: image.Rect(0, 0, 100, 10), swatches: swatches(3, 1), proportional: true, wantSizes: []int{75, 25}},
+		// Every share is 2.5 pixels, which rounds up on its own and used to overrun the strip
+		{name: "proportional halves", rect: image.Rect(0, 0, 50, 10), swatches: swatches(manyHalves...), proportional: true,
+			wantSizes: []int{3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2, 3, 2}},
+		{name: "vertical offset", rect: image.Rect(10, 20, 30, 50), swatches: swatches(2, 1), proportional: true, vertical: true, wantSizes: []int{20, 10}},
+		{name: "no counts", rect: image.Rect(0, 0, 10, 10), swatches: swatches(0, 0, 0), proportional: true, wantSizes: []int{3, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := stripBlocks(tt.rect, tt.swatches, tt.proportional, tt.vertical)
+			if len(blocks) != len(tt.wantSizes) {
+				t.Fatalf("got %d blocks, want %d", len(blocks), len(tt.wantSizes))
+			}
+
+			// Blocks tile the strip exactly: contiguous, inside it and ending at its far edge
+			next := tt.rect.Min
+			for i, block := range blocks {
+				size, start, cross := block.Dx(), block.Min.X, block.Dy() == tt.rect.Dy()
+				if tt.vertical {
+					size, start, cross = block.Dy(), block.Min.Y, block.Dx() == tt.rect.Dx()
+				}
+				if size != tt.wantSizes[i] {
+					t.Errorf("block %d size = %d, want %d", i, size, tt.wantSizes[i])
+				}
+				if (!tt.vertical && start != next.X) || (tt.vertical && start != next.Y) || !cross || !block.In(tt.rect) {
+					t.Errorf("block %d = %v doesn't continue the strip %v at %v", i, block, tt.rect, next)
+				}
+				next = image.Pt(block.Max.X, block.Max.Y)
+			}
+			if last := blocks[len(blocks)-1]; last.Max != tt.rect.Max {
+				t.Errorf("last block ends at %v, want %v", last.Max, tt.rect.Max)
+			}
+		})
+	}
+}
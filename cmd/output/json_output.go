@@ -0,0 +1,188 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// JSONSchemaVersion is bumped whenever the JSON and NDJSON output changes incompatibly
+const JSONSchemaVersion = 1
+
+// JSONDocument is the top-level object written by the json format
+type JSONDocument struct {
+	SchemaVersion int         `json:"schema_version"`
+	Images        []JSONImage `json:"images"`
+}
+
+// JSONImage describes the results for a single image. The ndjson format writes one per line,
+// with SchemaVersion set on each.
+type JSONImage struct {
+	SchemaVersion int                `json:"schema_version,omitempty"`
+	File          string             `json:"file"`
+	Error         string             `json:"error,omitempty"`
+	Summary       *JSONSummary       `json:"summary,omitempty"`
+	Colors        []JSONSwatch       `json:"colors,omitempty"`
+	Quantizers    []JSONQuantizer    `json:"quantizers"`
+	TimingsMs     map[string]float64 `json:"timings_ms,omitempty"`
+}
+
+// JSONSummary holds the summary statistics of the full color extraction
+type JSONSummary struct {
+	TotalColors   int       `json:"total_colors"`
+	MostFrequent  JSONCount `json:"most_frequent"`
+	LeastFrequent JSONCount `json:"least_frequent"`
+}
+
+// JSONCount is a color with its number of occurrences
+type JSONCount struct {
+	Hex   string `json:"hex"`
+	Count int    `json:"count"`
+}
+
+// JSONQuantizer holds one quantizer's palette, ordered by descending count
+type JSONQuantizer struct {
+	Name       string       `json:"name"`
+	Swatches   []JSONSwatch `json:"swatches"`
+	DurationMs float64      `json:"duration_ms"`
+	Metrics    *JSONMetrics `json:"metrics,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// JSONMetrics holds the quality of a quantizer's palette. Rank orders the measured quantizers of
+// an image from best (1) to worst.
+type JSONMetrics struct {
+	MSE        float64 `json:"mse"`
+	PSNR       float64 `json:"psnr"`
+	SSIM       float64 `json:"ssim"`
+	MeanDeltaE float64 `json:"mean_delta_e"`
+	P95DeltaE  float64 `json:"p95_delta_e"`
+	Rank       int     `json:"rank"`
+}
+
+// JSONSwatch is a single palette entry
+type JSONSwatch struct {
+	Rank       int        `json:"rank"`
+	Hex        string     `json:"hex"`
+	Name       string     `json:"name,omitempty"`
+	RGB        [3]uint8   `json:"rgb"`
+	Lab        [3]float64 `json:"lab"`
+	Count      int        `json:"count"`
+	Percentage float64    `json:"percentage"`
+}
+
+// writeJSON writes all results as a single JSON document
+func writeJSON(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	document := JSONDocument{SchemaVersion: JSONSchemaVersion, Images: make([]JSONImage, 0, len(results))}
+	for _, result := range results {
+		document.Images = append(document.Images, NewJSONImage(result, opts))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// writeNDJSON writes one JSON object per image, one per line
+func writeNDJSON(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		image := NewJSONImage(result, opts)
+		image.SchemaVersion = JSONSchemaVersion
+		if err := encoder.Encode(image); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewJSONImage converts an image result into its JSON representation
+func NewJSONImage(result imageprocessor.ImageResult, opts Options) JSONImage {
+	image := JSONImage{File: result.FilePath, Quantizers: []JSONQuantizer{}}
+	if result.Err != nil {
+		image.Error = result.Err.Error()
+		return image
+	}
+
+	if colorResults, ok := result.Results["ColorExtractor"]; ok {
+		colorSummary := SummarizeColors(colorResults)
+		image.Summary = &JSONSummary{
+			TotalColors:   colorSummary.TotalColors,
+			MostFrequent:  JSONCount{Hex: colorSummary.MostFrequentColor, Count: colorSummary.MostFrequentCount},
+			LeastFrequent: JSONCount{Hex: colorSummary.LeastFrequentColor, Count: colorSummary.LeastFrequentCount},
+		}
+		if opts.IncludeFullColorExtract {
+			image.Colors = jsonSwatches(colorResults, opts.Namer)
+		}
+	}
+
+	ranks := make(map[string]int)
+	for i, name := range imageprocessor.RankQuantizers(result) {
+		ranks[name] = i + 1
+	}
+
+	for _, quantizerName := range result.Quantizers {
+		quantizer := JSONQuantizer{
+			Name:       quantizerName,
+			Swatches:   jsonSwatches(result.Results[quantizerName], opts.Namer),
+			DurationMs: milliseconds(result.Timings[quantizerName]),
+		}
+		if metrics, ok := result.Metrics[quantizerName]; ok {
+			quantizer.Metrics = &JSONMetrics{
+				MSE:        round(metrics.MSE, 3),
+				PSNR:       round(metrics.PSNR, 3),
+				SSIM:       round(metrics.SSIM, 4),
+				MeanDeltaE: round(metrics.MeanDeltaE, 3),
+				P95DeltaE:  round(metrics.P95DeltaE, 3),
+				Rank:       ranks[quantizerName],
+			}
+		}
+		if err, failed := result.Errors[quantizerName]; failed {
+			quantizer.Error = err.Error()
+		}
+		image.Quantizers = append(image.Quantizers, quantizer)
+	}
+
+	if len(result.Timings) > 0 {
+		image.TimingsMs = make(map[string]float64, len(result.Timings))
+		for stage, duration := range result.Timings {
+			image.TimingsMs[stage] = milliseconds(duration)
+		}
+	}
+
+	return image
+}
+
+// jsonSwatches converts a palette into swatches ordered by descending count
+func jsonSwatches(colors map[string]int, namer *palette.ColorNamer) []JSONSwatch {
+	total := imageprocessor.TotalCount(colors)
+	swatches := make([]JSONSwatch, 0, len(colors))
+	for i, swatch := range imageprocessor.SortedSwatches(colors) {
+		entry := JSONSwatch{Rank: i + 1, Hex: swatch.Hex, Name: swatchName(namer, swatch.Hex), Count: swatch.Count}
+		if c, err := colorful.Hex(swatch.Hex); err == nil {
+			r, g, b := c.RGB255()
+			l, a, bb := c.Lab()
+			entry.RGB = [3]uint8{r, g, b}
+			entry.Lab = [3]float64{round(l*100, 2), round(a*100, 2), round(bb*100, 2)}
+		}
+		if total > 0 {
+			entry.Percentage = round(float64(swatch.Count)*100/float64(total), 2)
+		}
+		swatches = append(swatches, entry)
+	}
+	return swatches
+}
+
+func milliseconds(d time.Duration) float64 {
+	return round(float64(d)/float64(time.Millisecond), 3)
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
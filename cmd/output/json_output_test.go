@@ -0,0 +1,137 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"colorsage/imageprocessor"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testResults are an image with a palette, a failed quantizer and metrics, and an image that failed
+func testResults() []imageprocessor.ImageResult {
+	return []imageprocessor.ImageResult{
+		{
+			FilePath: "a.png",
+			Results: map[string]map[string]int{
+				"ColorExtractor":  {"#ff0000": 2, "#fe0000": 1, "#0000ff": 1},
+				"KMeansQuantizer": {"#ff0000": 3, "#0000ff": 1},
+			},
+			Quantizers: []string{"KMeansQuantizer", "Broken"},
+			Timings:    map[string]time.Duration{"ColorExtractor": 2 * time.Millisecond, "KMeansQuantizer": 1500 * time.Microsecond},
+			Errors:     map[string]error{"Broken": errors.New("boom")},
+			Metrics:    map[string]imageprocessor.QualityMetrics{"KMeansQuantizer": {MSE: 1.23456, PSNR: 47.2, SSIM: 0.99991, MeanDeltaE: 0.5, P95DeltaE: 1.25}},
+		},
+		{FilePath: "missing.png", Err: errors.New("no such file")},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "json", testResults(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var document JSONDocument
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	want := JSONDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Images: []JSONImage{
+			{
+				File: "a.png",
+				Summary: &JSONSummary{
+					TotalColors:   3,
+					MostFrequent:  JSONCount{Hex: "#ff0000", Count: 2},
+					LeastFrequent: JSONCount{Hex: "#fe0000", Count: 1},
+				},
+				Quantizers: []JSONQuantizer{
+					{
+						Name: "KMeansQuantizer",
+						Swatches: []JSONSwatch{
+							{Rank: 1, Hex: "#ff0000", RGB: [3]uint8{255, 0, 0}, Lab: [3]float64{53.24, 80.09, 67.2}, Count: 3, Percentage: 75},
+							{Rank: 2, Hex: "#0000ff", RGB: [3]uint8{0, 0, 255}, Lab: [3]float64{32.3, 79.19, -107.87}, Count: 1, Percentage: 25},
+						},
+						DurationMs: 1.5,
+						Metrics:    &JSONMetrics{MSE: 1.235, PSNR: 47.2, SSIM: 0.9999, MeanDeltaE: 0.5, P95DeltaE: 1.25, Rank: 1},
+					},
+					{Name: "Broken", Swatches: []JSONSwatch{}, Error: "boom"},
+				},
+				TimingsMs: map[string]float64{"ColorExtractor": 2, "KMeansQuantizer": 1.5},
+			},
+			{File: "missing.png", Error: "no such file", Quantizers: []JSONQuantizer{}},
+		},
+	}
+	if !reflect.DeepEqual(document, want) {
+		got, _ := json.MarshalIndent(document, "", "  ")
+		wantJSON, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("document =\n%s\nwant\n%s", got, wantJSON)
+	}
+
+	// The schema's key names are part of its contract
+	var raw struct {
+		SchemaVersion *int `json:"schema_version"`
+		Images        []map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.SchemaVersion == nil || *raw.SchemaVersion != 1 {
+		t.Error("schema_version is missing or not 1")
+	}
+	for _, key := range []string{"file", "summary", "quantizers", "timings_ms"} {
+		if _, ok := raw.Images[0][key]; !ok {
+			t.Errorf("image has no %q key", key)
+		}
+	}
+	if _, ok := raw.Images[0]["colors"]; ok {
+		t.Error("image has the full color extract without IncludeFullColorExtract")
+	}
+}
+
+func TestWriteJSONFullColorExtract(t *testing.T) {
+	image := NewJSONImage(testResults()[0], Options{IncludeFullColorExtract: true})
+	if len(image.Colors) != 3 || image.Colors[0].Hex != "#ff0000" || image.Colors[0].Percentage != 50 {
+		t.Errorf("Colors = %+v, want the 3 extracted colors, most frequent first", image.Colors)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, "ndjson", testResults(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var files []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var image JSONImage
+		if err := json.Unmarshal(scanner.Bytes(), &image); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if image.SchemaVersion != JSONSchemaVersion {
+			t.Errorf("line for %s has schema version %d, want %d", image.File, image.SchemaVersion, JSONSchemaVersion)
+		}
+		files = append(files, image.File)
+	}
+	if want := []string{"a.png", "missing.png"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("lines describe %v, want %v", files, want)
+	}
+}
+
+func TestSummarizeColorsTies(t *testing.T) {
+	colors := map[string]int{"#cccccc": 1, "#aaaaaa": 1, "#bbbbbb": 1}
+	for i := 0; i < 10; i++ {
+		summary := SummarizeColors(colors)
+		if summary.MostFrequentColor != "#aaaaaa" || summary.LeastFrequentColor != "#cccccc" {
+			t.Fatalf("SummarizeColors = %+v, want ties broken by hex", summary)
+		}
+	}
+	if summary := SummarizeColors(nil); summary != (ColorSummary{}) {
+		t.Errorf("SummarizeColors(nil) = %+v, want an empty summary", summary)
+	}
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"image"
+	"strings"
+	"testing"
+)
+
+// testNamer names swatches from the ISCC-NBS centroids
+func testNamer(t *testing.T) *palette.ColorNamer {
+	t.Helper()
+	namer, err := palette.NewColorNamer(palette.DictionaryISCCNBS, palette.MatchCIEDE2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return namer
+}
+
+// namedResults has one palette of two ISCC-NBS centroids
+func namedResults() []imageprocessor.ImageResult {
+	colors := map[string]int{"#be0032": 3, "#0067a5": 1}
+	return []imageprocessor.ImageResult{{
+		FilePath:   "a.png",
+		Results:    map[string]map[string]int{"ColorExtractor": colors, "KMeansQuantizer": colors},
+		Quantizers: []string{"KMeansQuantizer"},
+	}}
+}
+
+func TestWriteResultsNames(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"text", []string{
+			"    - Color #be0032 (vivid red): 3 occurrences",
+			"    - Color #0067a5 (strong blue): 1 occurrences",
+		}},
+		{"raw", []string{
+			"File: a.png, Quantizer: KMeansQuantizer, Color: #be0032 (vivid red), Occurrences: 3",
+			"File: a.png, Quantizer: KMeansQuantizer, Color: #0067a5 (strong blue), Occurrences: 1",
+		}},
+		{"json", []string{`"name": "vivid red"`, `"name": "strong blue"`}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := WriteResults(&buf, test.format, namedResults(), Options{Namer: testNamer(t)}); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s output is missing %q:\n%s", test.format, want, buf.String())
+			}
+		}
+
+		// Without a namer, only hex codes are written
+		buf.Reset()
+		if err := WriteResults(&buf, test.format, namedResults(), Options{}); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), "vivid red") {
+			t.Errorf("%s output names colors without a namer", test.format)
+		}
+	}
+}
+
+func TestSwatchLabelNames(t *testing.T) {
+	swatch := imageprocessor.Swatch{Hex: "#be0032", Count: 1}
+	measure := func(text string) int { return len(text) * 6 }
+	tests := []struct {
+		name  string
+		width int
+		want  string
+	}{
+		{"vivid red", 200, "vivid red 25.0%"},
+		{"vivid red", 60, "vivid red"},
+		// A name too long for the block falls back to the hex code
+		{"very deep purplish red", 60, "#be0032"},
+		{"", 200, "#be0032 25.0%"},
+	}
+	for _, test := range tests {
+		got := swatchLabel(swatch, test.name, 4, image.Rect(0, 0, test.width, 50), 12, measure)
+		if got != test.want {
+			t.Errorf("swatchLabel(%q, width %d) = %q, want %q", test.name, test.width, got, test.want)
+		}
+	}
+}
+
+func TestWriteSVGNames(t *testing.T) {
+	colors := map[string]int{"#be0032": 3, "#0067a5": 1}
+	doc := writeTestSVG(t, colors, PaletteOptions{Labels: true, BlockSize: 150, Namer: testNamer(t)})
+	if len(doc.Rects) != 2 || doc.Rects[0].Title != "#be0032 (vivid red)" || doc.Rects[1].Title != "#0067a5 (strong blue)" {
+		t.Errorf("rect titles = %+v, want hex codes with names", doc.Rects)
+	}
+	if len(doc.Texts) != 2 || doc.Texts[0] != "vivid red 75.0%" || doc.Texts[1] != "strong blue 25.0%" {
+		t.Errorf("labels = %q, want names with coverage", doc.Texts)
+	}
+}
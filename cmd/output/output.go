@@ -1,25 +1,125 @@
-// cmd/output/output.go
 package output
 
 import (
-	"colorsage/config"
 	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 )
 
+// Options controls how results are rendered
+type Options struct {
+	Format                  string // One of Formats(); empty picks table or raw depending on the terminal
+	Raw                     bool   // Output raw results without UI elements
+	IncludeFullColorExtract bool   // Include every extracted color, not only the quantized palettes
+	OmitHeader              bool   // Leave out column headers, e.g. when appending to an existing file
+
+	Namer *palette.ColorNamer // Annotates swatches with color names when set
+}
+
+// Formatter writes results in a single output format
+type Formatter func(w io.Writer, results []imageprocessor.ImageResult, opts Options) error
+
+// formatters maps format names to their writers
+var formatters = map[string]Formatter{
+	"table":  writeTable,
+	"raw":    writeRaw,
+	"text":   writeText,
+	"json":   writeJSON,
+	"ndjson": writeNDJSON,
+	"csv":    writeCSV,
+	"tsv":    writeTSV,
+	"html":   writeHTML,
+}
+
+// Formats returns the names of all supported output formats
+func Formats() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsFormat reports whether name is a supported output format
+func IsFormat(name string) bool {
+	_, ok := formatters[name]
+	return ok
+}
+
+// WriteResults writes results to w in the named format
+func WriteResults(w io.Writer, format string, results []imageprocessor.ImageResult, opts Options) error {
+	formatter, ok := formatters[format]
+	if !ok {
+		return fmt.Errorf("unknown output format %q. Supported formats: %s", format, strings.Join(Formats(), ", "))
+	}
+	return formatter(w, results, opts)
+}
+
 // DisplayResults coordinates the display of results, choosing the appropriate output methods
-func DisplayResults(results []imageprocessor.ImageResult) {
-	if config.RawOutput || !IsOutputTerminal() {
-		displayRawResults(results)
-	} else {
-		displayPrettyResults(results)
+func DisplayResults(results []imageprocessor.ImageResult, opts Options) {
+	format := opts.Format
+	if format == "" {
+		format = "table"
+		if opts.Raw || !IsOutputTerminal() {
+			format = "raw"
+		}
 	}
+
+	if err := WriteResults(os.Stdout, format, results, opts); err != nil {
+		fmt.Println("Error displaying results:", err)
+	}
+}
+
+// AppendableFormats lists the line-oriented formats that stay valid when appended to an existing file.
+// The others are single documents, so appending would leave two of them in one file.
+var AppendableFormats = []string{"raw", "text", "ndjson", "csv", "tsv"}
+
+// ResultsFileFormat resolves the format of a results file: inferred from the file extension when
+// possible, else the requested format, with table falling back to text or raw.
+func ResultsFileFormat(filename string, opts Options) string {
+	format := FormatForPath(filename)
+	if format == "" {
+		format = opts.Format
+	}
+	if format == "" || format == "table" {
+		format = "text"
+		if opts.Raw || !IsOutputTerminal() {
+			format = "raw"
+		}
+	}
+	return format
 }
 
-// WriteResultsToFile coordinates the writing of results to a file, choosing the appropriate output methods
-func WriteResultsToFile(filename string, results []imageprocessor.ImageResult) {
-	if config.RawOutput || !IsOutputTerminal() {
-		writeRawResultsToFile(filename, results)
-	} else {
-		writePrettyResultsToFile(filename, results)
+// WriteResultsToFile coordinates the writing of results to a file, choosing the appropriate output methods.
+// The format is inferred from the file extension when possible. It returns the path actually written.
+func WriteResultsToFile(filename, mode string, results []imageprocessor.ImageResult, opts Options) (string, error) {
+	format := ResultsFileFormat(filename, opts)
+	if !IsFormat(format) {
+		return "", fmt.Errorf("unknown output format %q. Supported formats: %s", format, strings.Join(Formats(), ", "))
 	}
+	if mode == FileModeAppend && !slices.Contains(AppendableFormats, format) {
+		return "", fmt.Errorf("can't append %s output to a file. Appendable formats: %s", format, strings.Join(AppendableFormats, ", "))
+	}
+
+	path, err := resultsFilePath(filename, mode)
+	if err != nil {
+		return "", err
+	}
+
+	appendExisting := mode == FileModeAppend
+	if appendExisting && hasContent(path) {
+		// Don't repeat column headers in the middle of the file
+		opts.OmitHeader = true
+	}
+
+	err = WriteFileAtomic(path, appendExisting, func(w io.Writer) error {
+		return WriteResults(w, format, results, opts)
+	})
+	return path, err
 }
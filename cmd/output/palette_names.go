@@ -0,0 +1,130 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultPaletteNameTemplate reproduces the historical palette file names
+const DefaultPaletteNameTemplate = "{{.Dir}}/{{.Base}}_{{.Quantizer}}_palette{{.Ext}}"
+
+// PaletteNameData is the data available to palette filename templates
+type PaletteNameData struct {
+	Dir       string // Directory the palette goes to: --palette-dir, the current directory or the source's directory
+	SourceDir string // Directory of the source image
+	Name      string // File name of the source image, e.g. photo.jpg
+	Base      string // File name of the source image without its extension, e.g. photo
+	Quantizer string // Name of the quantizer that produced the palette
+	Ext       string // Extension of the palette file, including the dot, e.g. .png or .card.png
+}
+
+// PaletteNamer builds palette file paths from a template and detects when two palettes would be
+// written to the same path in one run
+type PaletteNamer struct {
+	dir          string
+	inCurrentDir bool
+	template     *template.Template
+	used         map[string]string // Generated path to the source image it belongs to
+}
+
+// NewPaletteNamer parses the filename template. Palettes go to dir if set, otherwise to the current
+// directory with inCurrentDir, and next to their source image by default.
+func NewPaletteNamer(dir, pattern string, inCurrentDir bool) (*PaletteNamer, error) {
+	if pattern == "" {
+		pattern = DefaultPaletteNameTemplate
+	}
+	tmpl, err := template.New("palette-name").Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid palette name template: %v", err)
+	}
+
+	namer := &PaletteNamer{dir: dir, inCurrentDir: inCurrentDir, template: tmpl, used: map[string]string{}}
+	// Catch references to unknown fields before any image is processed
+	if _, err := namer.render(PaletteNameData{}); err != nil {
+		return nil, err
+	}
+	return namer, nil
+}
+
+// Name returns the path of the palette file for a source image, quantizer and extension. It fails if
+// the path was already handed out for another palette in this run or would overwrite the source image.
+func (n *PaletteNamer) Name(filePath, quantizerName, extension string) (string, error) {
+	baseName := filepath.Base(filePath)
+	data := PaletteNameData{
+		Dir:       n.dirFor(filePath),
+		SourceDir: filepath.Dir(filePath),
+		Name:      baseName,
+		Base:      strings.TrimSuffix(baseName, filepath.Ext(baseName)),
+		Quantizer: quantizerName,
+		Ext:       extension,
+	}
+
+	path, err := n.render(data)
+	if err != nil {
+		return "", err
+	}
+	return n.claim(path, filePath)
+}
+
+// SourceName returns the path of a file derived from the whole source image rather than a palette,
+// such as a simulation of it: the source's name without extension followed by suffix, in the
+// directory palettes go to.
+func (n *PaletteNamer) SourceName(filePath, suffix string) (string, error) {
+	baseName := filepath.Base(filePath)
+	path := filepath.Join(n.dirFor(filePath), strings.TrimSuffix(baseName, filepath.Ext(baseName))+suffix)
+	return n.claim(path, filePath)
+}
+
+// dirFor returns the directory the palettes of a source image go to
+func (n *PaletteNamer) dirFor(filePath string) string {
+	switch {
+	case n.dir != "":
+		return n.dir
+	case n.inCurrentDir:
+		return "."
+	default:
+		return filepath.Dir(filePath)
+	}
+}
+
+// claim records a generated path, failing if it was handed out before or is the source image itself,
+// and creates its directory
+func (n *PaletteNamer) claim(path, filePath string) (string, error) {
+	if samePath(path, filePath) {
+		return "", fmt.Errorf("palette file %s would overwrite its source image", path)
+	}
+	key := absPath(path)
+	if source, ok := n.used[key]; ok {
+		return "", fmt.Errorf("palette file %s for %s collides with the one generated for %s; include {{.SourceDir}} or {{.Ext}} in the palette name template", path, filePath, source)
+	}
+	n.used[key] = filePath
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+func (n *PaletteNamer) render(data PaletteNameData) (string, error) {
+	var sb strings.Builder
+	if err := n.template.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("invalid palette name template: %v", err)
+	}
+	return filepath.Clean(sb.String()), nil
+}
+
+func samePath(a, b string) bool {
+	return absPath(a) == absPath(b)
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
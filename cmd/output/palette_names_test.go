@@ -0,0 +1,114 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaletteNamerName(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "photos", "beach.jpg")
+	tests := []struct {
+		name         string
+		dir          string
+		pattern      string
+		inCurrentDir bool
+		want         string
+	}{
+		{"default template next to the source", "", "", false, filepath.Join(dir, "photos", "beach_KMeansQuantizer_palette.png")},
+		{"default template in the current directory", "", "", true, "beach_KMeansQuantizer_palette.png"},
+		{"palette dir", filepath.Join(dir, "palettes"), "", false, filepath.Join(dir, "palettes", "beach_KMeansQuantizer_palette.png")},
+		{"palette dir overrides the current directory", filepath.Join(dir, "palettes"), "", true, filepath.Join(dir, "palettes", "beach_KMeansQuantizer_palette.png")},
+		{"every field", filepath.Join(dir, "out"), "{{.Dir}}/{{.Quantizer}}/{{.Name}}-{{.Base}}{{.Ext}}", false, filepath.Join(dir, "out", "KMeansQuantizer", "beach.jpg-beach.png")},
+		{"source directory", filepath.Join(dir, "out"), "{{.SourceDir}}/swatches/{{.Base}}{{.Ext}}", false, filepath.Join(dir, "photos", "swatches", "beach.png")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewPaletteNamer(test.dir, test.pattern, test.inCurrentDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := namer.Name(source, "KMeansQuantizer", ".png")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("Name = %s, want %s", got, test.want)
+			}
+			if info, err := os.Stat(filepath.Dir(got)); err != nil || !info.IsDir() {
+				t.Errorf("directory of %s wasn't created: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestPaletteNamerSourceName(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "beach.jpg")
+
+	namer, err := NewPaletteNamer("", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := namer.SourceName(source, "_deuteranopia.png"); err != nil || got != filepath.Join(dir, "beach_deuteranopia.png") {
+		t.Errorf("SourceName = %s, %v", got, err)
+	}
+
+	namer, err = NewPaletteNamer(filepath.Join(dir, "palettes"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := namer.SourceName(source, "_deuteranopia.png"); err != nil || got != filepath.Join(dir, "palettes", "beach_deuteranopia.png") {
+		t.Errorf("SourceName with --palette-dir = %s, %v", got, err)
+	}
+}
+
+func TestPaletteNamerCollisions(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a", "beach.jpg")
+	second := filepath.Join(dir, "b", "beach.jpg")
+
+	namer, err := NewPaletteNamer(filepath.Join(dir, "palettes"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := namer.Name(first, "KMeansQuantizer", ".png"); err != nil {
+		t.Fatal(err)
+	}
+	// Another format or quantizer for the same source gets its own file
+	if _, err := namer.Name(first, "KMeansQuantizer", ".svg"); err != nil {
+		t.Errorf("another extension: %v", err)
+	}
+	if _, err := namer.Name(first, "MedianCutQuantizer", ".png"); err != nil {
+		t.Errorf("another quantizer: %v", err)
+	}
+
+	// Sources with the same name in different directories land on the same path in --palette-dir
+	_, err = namer.Name(second, "KMeansQuantizer", ".png")
+	if err == nil || !strings.Contains(err.Error(), "collides with the one generated for "+first) {
+		t.Errorf("colliding name error = %v", err)
+	}
+	// SourceName draws from the same set of paths as Name
+	if _, err := namer.SourceName(first, "_KMeansQuantizer_palette.svg"); err == nil {
+		t.Error("SourceName reused a path handed out by Name")
+	}
+
+	// Generated files never replace their source image
+	overwriting, err := NewPaletteNamer("", "{{.Dir}}/{{.Name}}", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := overwriting.Name(first, "KMeansQuantizer", ".png"); err == nil || !strings.Contains(err.Error(), "would overwrite its source image") {
+		t.Errorf("overwriting the source error = %v", err)
+	}
+}
+
+func TestNewPaletteNamerInvalidTemplate(t *testing.T) {
+	for _, pattern := range []string{"{{.Dir}/x", "{{.Quantiser}}.png"} {
+		if _, err := NewPaletteNamer("", pattern, false); err == nil || !strings.Contains(err.Error(), "invalid palette name template") {
+			t.Errorf("NewPaletteNamer(%q) error = %v", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PaletteOptions controls how a single palette is exported
+type PaletteOptions struct {
+	Name    string // Palette or swatch group name, e.g. "image.png – KMeansQuantizer"
+	Columns int    // Column hint for palette formats that support one; 0 picks a default
+	Lab     bool   // Store colors as Lab instead of RGB in formats that support both
+
+	// Rendering options for image formats
+	Layout       string // One of Layouts; empty means horizontal
+	Sort         string // One of palette.SortOrders; empty means by frequency
+	Proportional bool   // Size swatches by their share of the counts
+	Labels       bool   // Draw hex labels on the swatches
+	BlockSize    int    // Swatch edge length in pixels; 0 means 50
+	Donut        bool   // Add a donut chart of coverage to vector formats
+
+	Namer *palette.ColorNamer // Labels swatches with color names instead of hex codes when set
+}
+
+// PaletteExporter writes a single palette in one file format
+type PaletteExporter struct {
+	Extension string
+	Write     func(w io.Writer, palette map[string]int, opts PaletteOptions) error
+}
+
+// paletteExporters maps palette format names to their exporters
+var paletteExporters = map[string]PaletteExporter{
+	"png":      {Extension: ".png", Write: writePalettePNG},
+	"svg":      {Extension: ".svg", Write: writeSVG},
+	"gpl":      {Extension: ".gpl", Write: writeGPL},
+	"ase":      {Extension: ".ase", Write: writeASE},
+	"aco":      {Extension: ".aco", Write: writeACO},
+	"css":      {Extension: ".css", Write: writeCSS},
+	"scss":     {Extension: ".scss", Write: writeSCSS},
+	"tailwind": {Extension: ".tailwind.config.js", Write: writeTailwind},
+	"tokens":   {Extension: ".tokens.json", Write: writeDesignTokens},
+}
+
+// PaletteFormats returns the names of all supported palette file formats
+func PaletteFormats() []string {
+	names := make([]string, 0, len(paletteExporters))
+	for name := range paletteExporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PaletteExtension returns the file extension for a palette format
+func PaletteExtension(format string) (string, error) {
+	exporter, ok := paletteExporters[format]
+	if !ok {
+		return "", fmt.Errorf("unknown palette format %q. Supported formats: %s", format, strings.Join(PaletteFormats(), ", "))
+	}
+	return exporter.Extension, nil
+}
+
+// WritePalette writes a palette to w in the named format
+func WritePalette(w io.Writer, format string, palette map[string]int, opts PaletteOptions) error {
+	exporter, ok := paletteExporters[format]
+	if !ok {
+		return fmt.Errorf("unknown palette format %q. Supported formats: %s", format, strings.Join(PaletteFormats(), ", "))
+	}
+	return exporter.Write(w, palette, opts)
+}
+
+// WritePaletteFile atomically writes a palette to filePath in the named format
+func WritePaletteFile(filePath, format string, palette map[string]int, opts PaletteOptions) error {
+	if _, err := PaletteExtension(format); err != nil {
+		return err
+	}
+	return WriteFileAtomic(filePath, false, func(w io.Writer) error {
+		return WritePalette(w, format, palette, opts)
+	})
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"sort"
+	"strings"
+)
+
+// remapEncoders maps indexed image formats to their encoders
+var remapEncoders = map[string]func(w io.Writer, img *image.Paletted) error{
+	"png": func(w io.Writer, img *image.Paletted) error { return png.Encode(w, img) },
+	"gif": func(w io.Writer, img *image.Paletted) error {
+		img = binaryTransparency(img)
+		return gif.Encode(w, img, &gif.Options{NumColors: len(img.Palette)})
+	},
+}
+
+// binaryTransparency makes translucent palette entries opaque or transparent, whichever is nearer,
+// for GIF, which has a single transparent entry and no partial alpha
+func binaryTransparency(img *image.Paletted) *image.Paletted {
+	pal := make(color.Palette, len(img.Palette))
+	indices := make([]uint8, len(img.Palette))
+	transparent, changed := -1, false
+	for i, entry := range img.Palette {
+		indices[i] = uint8(i)
+		c := color.NRGBAModel.Convert(entry).(color.NRGBA)
+		changed = changed || c.A != 255
+		if c.A >= 128 {
+			pal[i] = color.RGBA{R: c.R, G: c.G, B: c.B, A: 255}
+			continue
+		}
+		// Other transparent entries would be drawn black, so their pixels use the first one
+		pal[i] = color.RGBA{}
+		if transparent < 0 {
+			transparent = i
+		}
+		indices[i] = uint8(transparent)
+	}
+	if !changed {
+		return img
+	}
+
+	out := image.NewPaletted(img.Rect, pal)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			out.SetColorIndex(x, y, indices[img.ColorIndexAt(x, y)])
+		}
+	}
+	return out
+}
+
+// RemapFormats returns the names of the formats remapped images can be written in
+func RemapFormats() []string {
+	names := make([]string, 0, len(remapEncoders))
+	for name := range remapEncoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteRemappedImage writes an indexed image as a paletted PNG or GIF
+func WriteRemappedImage(filePath, format string, img *image.Paletted) error {
+	encode, ok := remapEncoders[format]
+	if !ok {
+		return fmt.Errorf("unknown remap format %q. Supported formats: %s", format, strings.Join(RemapFormats(), ", "))
+	}
+	return WriteFileAtomic(filePath, false, func(w io.Writer) error {
+		return encode(w, img)
+	})
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"colorsage/palette"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RolesReport holds the vibrant and muted roles of one quantizer's palette
+type RolesReport struct {
+	File      string
+	Quantizer string
+	Swatches  []palette.VibrantSwatch
+}
+
+type jsonRolesReport struct {
+	File      string          `json:"file"`
+	Quantizer string          `json:"quantizer"`
+	Roles     []jsonRoleColor `json:"roles"`
+}
+
+type jsonRoleColor struct {
+	Role       string `json:"role"`
+	Hex        string `json:"hex"`
+	Population int    `json:"population"`
+	TitleText  string `json:"title_text"`
+	BodyText   string `json:"body_text"`
+}
+
+// rolesWriters maps role report formats to their writers
+var rolesWriters = map[string]func(w io.Writer, reports []RolesReport) error{
+	"table": writeRolesTable,
+	"json":  writeRolesJSON,
+}
+
+// RolesFormats returns the names of all supported role report formats
+func RolesFormats() []string {
+	names := make([]string, 0, len(rolesWriters))
+	for name := range rolesWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteRolesReports writes role reports in the named format
+func WriteRolesReports(w io.Writer, format string, reports []RolesReport) error {
+	writer, ok := rolesWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown roles format %q. Supported formats: %s", format, strings.Join(RolesFormats(), ", "))
+	}
+	return writer(w, reports)
+}
+
+func writeRolesTable(w io.Writer, reports []RolesReport) error {
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s – %s\n", report.File, report.Quantizer)
+
+		table := tablewriter.NewWriter(w)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Role", "Color", "Population", "Title Text", "Body Text"})
+		for _, swatch := range report.Swatches {
+			table.Append([]string{
+				swatch.Role,
+				BackgroundColor(swatch.Hex) + swatch.Hex + Reset,
+				fmt.Sprintf("%d", swatch.Population),
+				textSample(swatch.Hex, swatch.TitleText),
+				textSample(swatch.Hex, swatch.BodyText),
+			})
+		}
+		table.Render()
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// textSample shows a #rrggbbaa text color in itself, blended over the background it is meant for
+func textSample(background, text string) string {
+	if len(text) != 9 {
+		return text
+	}
+	bg, err := colorful.Hex(background)
+	fg, err2 := colorful.Hex(text[:7])
+	var alpha int
+	_, err3 := fmt.Sscanf(text[7:], "%02x", &alpha)
+	if err != nil || err2 != nil || err3 != nil {
+		return text
+	}
+	br, bgG, bb := bg.RGB255()
+	fr, fgG, fb := bg.BlendRgb(fg, float64(alpha)/255).RGB255()
+	return fmt.Sprintf("\033[48;2;%d;%d;%dm\033[38;2;%d;%d;%dm%s%s", br, bgG, bb, fr, fgG, fb, text, Reset)
+}
+
+func writeRolesJSON(w io.Writer, reports []RolesReport) error {
+	document := make([]jsonRolesReport, 0, len(reports))
+	for _, report := range reports {
+		entry := jsonRolesReport{File: report.File, Quantizer: report.Quantizer, Roles: []jsonRoleColor{}}
+		for _, swatch := range report.Swatches {
+			entry.Roles = append(entry.Roles, jsonRoleColor(swatch))
+		}
+		document = append(document, entry)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,123 @@
+package output
+
+import (
+	"bufio"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"html"
+	"io"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// SVG label metrics: monospace glyphs are about 0.6 em wide
+const (
+	svgFontSize   = 11
+	svgGlyphWidth = 0.6 * svgFontSize
+)
+
+// Donut chart geometry, relative to the block size
+const (
+	donutRadius = 1.6 // Radius of the ring's center line
+	donutWidth  = 0.8 // Thickness of the ring
+	donutMargin = 0.4 // Space around the chart
+)
+
+// writeSVG writes a palette as SVG with the same layout, order and labels as the PNG renderer,
+// optionally followed by a donut chart of each color's coverage.
+func writeSVG(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	swatches, err := palette.SortSwatches(colors, opts.Sort)
+	if err != nil {
+		return err
+	}
+	canvas, blocks, err := layoutSwatches(swatches, opts)
+	if err != nil {
+		return err
+	}
+	total := imageprocessor.TotalCount(colors)
+
+	blockSize := float64(opts.BlockSize)
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+
+	width, height := float64(canvas.Dx()), float64(canvas.Dy())
+	radius := donutRadius * blockSize
+	chartSize := 2 * (radius + (donutWidth/2+donutMargin)*blockSize)
+	chartX := width
+	if opts.Donut {
+		width += chartSize
+		height = math.Max(height, chartSize)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" viewBox=\"0 0 %g %g\">\n", width, height, width, height)
+	if opts.Name != "" {
+		fmt.Fprintf(bw, "  <title>%s</title>\n", html.EscapeString(opts.Name))
+	}
+
+	for i, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return err
+		}
+		block := blocks[i]
+		name := swatchName(opts.Namer, swatch.Hex)
+		fmt.Fprintf(bw, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"><title>%s</title></rect>\n",
+			block.Min.X, block.Min.Y, block.Dx(), block.Dy(), swatch.Hex, html.EscapeString(withName(opts.Namer, swatch.Hex)))
+
+		if !opts.Labels {
+			continue
+		}
+		text := swatchLabel(swatch, name, total, block, svgFontSize, func(text string) int {
+			return int(math.Ceil(float64(len(text)) * svgGlyphWidth))
+		})
+		if text == "" {
+			continue
+		}
+		fill := "#000000"
+		if prefersWhiteText(c) {
+			fill = "#ffffff"
+		}
+		fmt.Fprintf(bw, "  <text x=\"%g\" y=\"%g\" fill=\"%s\" font-family=\"monospace\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"central\">%s</text>\n",
+			float64(block.Min.X)+float64(block.Dx())/2, float64(block.Min.Y)+float64(block.Dy())/2, fill, svgFontSize, html.EscapeString(text))
+	}
+
+	if opts.Donut && total > 0 {
+		writeSVGDonut(bw, swatches, total, chartX+chartSize/2, chartSize/2, radius, donutWidth*blockSize, opts.Labels)
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// writeSVGDonut draws a ring with one arc per swatch, sized by its share of the counts. Each arc is
+// a dashed circle stroke rotated to start where the previous one ended, beginning at 12 o'clock.
+func writeSVGDonut(w io.Writer, swatches []imageprocessor.Swatch, total int, cx, cy, radius, thickness float64, labels bool) {
+	circumference := 2 * math.Pi * radius
+
+	fmt.Fprintf(w, "  <g transform=\"rotate(-90 %g %g)\">\n", cx, cy)
+	offset := 0.0
+	for _, swatch := range swatches {
+		share := float64(swatch.Count) / float64(total)
+		length := share * circumference
+		fmt.Fprintf(w, "    <circle cx=\"%g\" cy=\"%g\" r=\"%g\" fill=\"none\" stroke=\"%s\" stroke-width=\"%g\" stroke-dasharray=\"%.3f %.3f\" stroke-dashoffset=\"%.3f\"><title>%s %.1f%%</title></circle>\n",
+			cx, cy, radius, swatch.Hex, thickness, length, circumference-length, -offset, swatch.Hex, share*100)
+		offset += length
+	}
+	fmt.Fprintln(w, "  </g>")
+
+	if labels {
+		// Label the most frequent color in the hole of the donut
+		top := swatches[0]
+		for _, swatch := range swatches {
+			if swatch.Count > top.Count {
+				top = swatch
+			}
+		}
+		fmt.Fprintf(w, "  <text x=\"%g\" y=\"%g\" font-family=\"monospace\" font-size=\"%d\" text-anchor=\"middle\" dominant-baseline=\"central\">%s %.1f%%</text>\n",
+			cx, cy, svgFontSize, top.Hex, float64(top.Count)*100/float64(total))
+	}
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// svgDocument is the part of the SVG output the tests check
+type svgDocument struct {
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+	Title  string  `xml:"title"`
+	Rects  []struct {
+		X     int    `xml:"x,attr"`
+		Y     int    `xml:"y,attr"`
+		Width int    `xml:"width,attr"`
+		Fill  string `xml:"fill,attr"`
+		Title string `xml:"title"`
+	} `xml:"rect"`
+	Texts  []string `xml:"text"`
+	Donuts []struct {
+		Transform string `xml:"transform,attr"`
+		Circles   []struct {
+			R          float64 `xml:"r,attr"`
+			Stroke     string  `xml:"stroke,attr"`
+			DashArray  string  `xml:"stroke-dasharray,attr"`
+			DashOffset float64 `xml:"stroke-dashoffset,attr"`
+			Title      string  `xml:"title"`
+		} `xml:"circle"`
+	} `xml:"g"`
+}
+
+func writeTestSVG(t *testing.T, colors map[string]int, opts PaletteOptions) svgDocument {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "svg", colors, opts); err != nil {
+		t.Fatal(err)
+	}
+	var doc svgDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid SVG: %v\n%s", err, buf.String())
+	}
+	return doc
+}
+
+func TestWriteSVG(t *testing.T) {
+	doc := writeTestSVG(t, testPalette, PaletteOptions{Name: "a <b> & c"})
+
+	if doc.Title != "a <b> & c" {
+		t.Errorf("title = %q", doc.Title)
+	}
+	if len(doc.Rects) != len(testPaletteOrder) {
+		t.Fatalf("got %d rects, want %d", len(doc.Rects), len(testPaletteOrder))
+	}
+	for i, hex := range testPaletteOrder {
+		rect := doc.Rects[i]
+		if rect.Fill != hex || rect.X != i*defaultBlockSize || rect.Y != 0 || rect.Width != defaultBlockSize {
+			t.Errorf("rect %d = %+v, want %s at x=%d", i, rect, hex, i*defaultBlockSize)
+		}
+	}
+	if doc.Width != float64(len(testPaletteOrder)*defaultBlockSize) || doc.Height != defaultBlockSize {
+		t.Errorf("size = %gx%g", doc.Width, doc.Height)
+	}
+	if len(doc.Texts) != 0 || len(doc.Donuts) != 0 {
+		t.Errorf("got labels %q and %d donuts without asking for them", doc.Texts, len(doc.Donuts))
+	}
+}
+
+func TestWriteSVGLabels(t *testing.T) {
+	doc := writeTestSVG(t, testPalette, PaletteOptions{Labels: true, BlockSize: 100})
+	if len(doc.Texts) != len(testPaletteOrder) {
+		t.Fatalf("got %d labels, want %d: %q", len(doc.Texts), len(testPaletteOrder), doc.Texts)
+	}
+	for i, hex := range testPaletteOrder {
+		if !strings.Contains(doc.Texts[i], hex) {
+			t.Errorf("label %d = %q, want it to contain %s", i, doc.Texts[i], hex)
+		}
+	}
+}
+
+func TestWriteSVGDonut(t *testing.T) {
+	doc := writeTestSVG(t, testPalette, PaletteOptions{Donut: true})
+	if len(doc.Donuts) != 1 {
+		t.Fatalf("got %d donuts, want 1", len(doc.Donuts))
+	}
+	donut := doc.Donuts[0]
+	if !strings.HasPrefix(donut.Transform, "rotate(-90 ") {
+		t.Errorf("donut transform = %q, want it to start at 12 o'clock", donut.Transform)
+	}
+	if len(donut.Circles) != len(testPaletteOrder) {
+		t.Fatalf("got %d arcs, want %d", len(donut.Circles), len(testPaletteOrder))
+	}
+
+	total := 0
+	for _, count := range testPalette {
+		total += count
+	}
+	radius := donutRadius * defaultBlockSize
+	circumference := 2 * math.Pi * radius
+	offset := 0.0
+	for i, hex := range testPaletteOrder {
+		arc := donut.Circles[i]
+		share := float64(testPalette[hex]) / float64(total)
+		var length, gap float64
+		if _, err := fmt.Sscanf(arc.DashArray, "%g %g", &length, &gap); err != nil {
+			t.Fatalf("arc %d dasharray %q: %v", i, arc.DashArray, err)
+		}
+
+		if arc.Stroke != hex || arc.R != radius {
+			t.Errorf("arc %d = %s r=%g, want %s r=%g", i, arc.Stroke, arc.R, hex, radius)
+		}
+		if math.Abs(length-share*circumference) > 0.01 || math.Abs(length+gap-circumference) > 0.01 {
+			t.Errorf("arc %d dasharray = %q, want %.3f of %.3f", i, arc.DashArray, share*circumference, circumference)
+		}
+		// Each arc starts where the previous one ended
+		if math.Abs(arc.DashOffset+offset) > 0.01 {
+			t.Errorf("arc %d dashoffset = %g, want %.3f", i, arc.DashOffset, -offset)
+		}
+		if want := fmt.Sprintf("%s %.1f%%", hex, share*100); arc.Title != want {
+			t.Errorf("arc %d title = %q, want %q", i, arc.Title, want)
+		}
+		offset += length
+	}
+	if math.Abs(offset-circumference) > 0.05 {
+		t.Errorf("arcs cover %.3f of %.3f", offset, circumference)
+	}
+
+	chartSize := 2 * (radius + (donutWidth/2+donutMargin)*defaultBlockSize)
+	if want := float64(len(testPaletteOrder)*defaultBlockSize) + chartSize; doc.Width != want || doc.Height != chartSize {
+		t.Errorf("size = %gx%g, want %gx%g", doc.Width, doc.Height, want, chartSize)
+	}
+}
+
+func TestWriteSVGDonutLabel(t *testing.T) {
+	doc := writeTestSVG(t, testPalette, PaletteOptions{Donut: true, Labels: true, BlockSize: 100})
+	want := fmt.Sprintf("#ff0000 %.1f%%", 30.0*100/66)
+	if len(doc.Texts) == 0 || doc.Texts[len(doc.Texts)-1] != want {
+		t.Errorf("donut label = %q, want %q last", doc.Texts, want)
+	}
+}
@@ -2,23 +2,22 @@
 package output
 
 import (
-	"colorsage/config"
 	"colorsage/imageprocessor"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
 )
 
-// displayPrettyResults shows results in a nice table format with colors
-func displayPrettyResults(results []imageprocessor.ImageResult) {
-	table := tablewriter.NewWriter(os.Stdout)
+// writeTable shows results in a nice table format with colors
+func writeTable(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
+	table := tablewriter.NewWriter(w)
 	table.SetHeader([]string{"File", "Quantizer", "Color", "Occurrences"})
 
 	for _, result := range results {
 		if result.Err != nil {
-			fmt.Printf(Red+"❌ Error processing file %s: %v"+Reset+"\n", result.FilePath, result.Err)
+			fmt.Fprintf(w, Red+"❌ Error processing file %s: %v"+Reset+"\n", result.FilePath, result.Err)
 			continue
 		}
 
@@ -29,122 +28,97 @@ func displayPrettyResults(results []imageprocessor.ImageResult) {
 		table.Append([]string{"", "Summary", fmt.Sprintf("Least Frequent: %s", colorSummary.LeastFrequentColor), fmt.Sprintf("%d", colorSummary.LeastFrequentCount)})
 
 		// Optionally, print full color extraction details
-		if config.IncludeFullColorExtract {
+		if opts.IncludeFullColorExtract {
 			if colorResults, ok := result.Results["ColorExtractor"]; ok {
-				for hex, count := range colorResults {
-					table.Append([]string{"", "ColorExtractor", fmt.Sprintf(BackgroundColor(hex)+"%s"+Reset, hex), fmt.Sprintf("%d", count)})
+				for _, swatch := range imageprocessor.SortedSwatches(colorResults) {
+					table.Append([]string{"", "ColorExtractor", fmt.Sprintf(BackgroundColor(swatch.Hex)+"%s"+Reset, withName(opts.Namer, swatch.Hex)), fmt.Sprintf("%d", swatch.Count)})
 				}
 			}
 		}
 
 		// Print the results for each quantizer
-		for _, quantizerName := range []string{"KMeansQuantizer", "MedianCutQuantizer", "AverageQuantizer"} {
+		for _, quantizerName := range result.Quantizers {
+			if err, failed := result.Errors[quantizerName]; failed {
+				table.Append([]string{"", quantizerName, Red + "Error: " + err.Error() + Reset, ""})
+				continue
+			}
 			if palette, ok := result.Results[quantizerName]; ok {
-				for hex, count := range palette {
-					table.Append([]string{"", quantizerName, fmt.Sprintf(BackgroundColor(hex)+"%s"+Reset, hex), fmt.Sprintf("%d", count)})
+				for _, swatch := range imageprocessor.SortedSwatches(palette) {
+					table.Append([]string{"", quantizerName, fmt.Sprintf(BackgroundColor(swatch.Hex)+"%s"+Reset, withName(opts.Namer, swatch.Hex)), fmt.Sprintf("%d", swatch.Count)})
 				}
 			}
+			if metrics, ok := result.Metrics[quantizerName]; ok {
+				table.Append([]string{"", quantizerName, "Quality: " + FormatMetrics(metrics), ""})
+			}
+		}
+		if ranking := formatRanking(result); ranking != "" {
+			table.Append([]string{"", "Ranking", ranking, ""})
 		}
 	}
 	table.SetRowLine(true)
 	table.Render()
+	return nil
 }
 
-// displayRawResults outputs results in a simple format suitable for piping
-func displayRawResults(results []imageprocessor.ImageResult) {
+// writeRaw outputs results in a simple format suitable for piping
+func writeRaw(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
 	for _, result := range results {
 		if result.Err != nil {
-			fmt.Printf("Error processing file %s: %v\n", result.FilePath, result.Err)
+			fmt.Fprintf(w, "Error processing file %s: %v\n", result.FilePath, result.Err)
 			continue
 		}
 
 		// Summary stats
 		colorSummary := SummarizeColors(result.Results["ColorExtractor"])
-		fmt.Printf("File: %s, Summary: Total Colors: %d\n", result.FilePath, colorSummary.TotalColors)
-		fmt.Printf("Most Frequent: %s, Occurrences: %d\n", colorSummary.MostFrequentColor, colorSummary.MostFrequentCount)
-		fmt.Printf("Least Frequent: %s, Occurrences: %d\n", colorSummary.LeastFrequentColor, colorSummary.LeastFrequentCount)
+		fmt.Fprintf(w, "File: %s, Summary: Total Colors: %d\n", result.FilePath, colorSummary.TotalColors)
+		fmt.Fprintf(w, "Most Frequent: %s, Occurrences: %d\n", colorSummary.MostFrequentColor, colorSummary.MostFrequentCount)
+		fmt.Fprintf(w, "Least Frequent: %s, Occurrences: %d\n", colorSummary.LeastFrequentColor, colorSummary.LeastFrequentCount)
 
 		// Optionally, print full color extraction details
-		if config.IncludeFullColorExtract {
+		if opts.IncludeFullColorExtract {
 			if colorResults, ok := result.Results["ColorExtractor"]; ok {
-				for hex, count := range colorResults {
-					fmt.Printf("File: %s, Quantizer: ColorExtractor, Color: %s, Occurrences: %d\n", result.FilePath, hex, count)
+				for _, swatch := range imageprocessor.SortedSwatches(colorResults) {
+					fmt.Fprintf(w, "File: %s, Quantizer: ColorExtractor, Color: %s, Occurrences: %d\n", result.FilePath, withName(opts.Namer, swatch.Hex), swatch.Count)
 				}
 			}
 		}
 
 		// Print the results for each quantizer
-		for _, quantizerName := range []string{"KMeansQuantizer", "MedianCutQuantizer", "AverageQuantizer"} {
+		for _, quantizerName := range result.Quantizers {
+			if err, failed := result.Errors[quantizerName]; failed {
+				fmt.Fprintf(w, "File: %s, Quantizer: %s, Error: %v\n", result.FilePath, quantizerName, err)
+				continue
+			}
 			if palette, ok := result.Results[quantizerName]; ok {
-				for hex, count := range palette {
-					fmt.Printf("File: %s, Quantizer: %s, Color: %s, Occurrences: %d\n", result.FilePath, quantizerName, hex, count)
+				for _, swatch := range imageprocessor.SortedSwatches(palette) {
+					fmt.Fprintf(w, "File: %s, Quantizer: %s, Color: %s, Occurrences: %d\n", result.FilePath, quantizerName, withName(opts.Namer, swatch.Hex), swatch.Count)
 				}
 			}
+			if metrics, ok := result.Metrics[quantizerName]; ok {
+				fmt.Fprintf(w, "File: %s, Quantizer: %s, Quality: %s\n", result.FilePath, quantizerName, FormatMetrics(metrics))
+			}
+		}
+		if ranking := formatRanking(result); ranking != "" {
+			fmt.Fprintf(w, "File: %s, Ranking: %s\n", result.FilePath, ranking)
 		}
 	}
+	return nil
 }
 
-// writePrettyResultsToFile writes formatted results to a file
-func writePrettyResultsToFile(filename string, results []imageprocessor.ImageResult) {
-	file, err := os.Create(filename)
-	if err != nil {
-		fmt.Println("Error creating results file:", err)
-		return
-	}
-	defer file.Close()
-
+// writeText writes results as indented, human-readable text
+func writeText(w io.Writer, results []imageprocessor.ImageResult, opts Options) error {
 	var sb strings.Builder
 	for _, result := range results {
-		sb.WriteString(formatResultsForFile(result))
+		sb.WriteString(formatResultsForFile(result, opts))
 		sb.WriteString("\n")
 	}
 
-	file.WriteString(sb.String())
-}
-
-// writeRawResultsToFile writes raw results to a file
-func writeRawResultsToFile(filename string, results []imageprocessor.ImageResult) {
-	file, err := os.Create(filename)
-	if err != nil {
-		fmt.Println("Error creating results file:", err)
-		return
-	}
-	defer file.Close()
-
-	for _, result := range results {
-		if result.Err != nil {
-			fmt.Fprintf(file, "Error processing file %s: %v\n", result.FilePath, result.Err)
-			continue
-		}
-
-		// Summary stats
-		colorSummary := SummarizeColors(result.Results["ColorExtractor"])
-		fmt.Fprintf(file, "File: %s, Summary: Total Colors: %d\n", result.FilePath, colorSummary.TotalColors)
-		fmt.Fprintf(file, "Most Frequent: %s, Occurrences: %d\n", colorSummary.MostFrequentColor, colorSummary.MostFrequentCount)
-		fmt.Fprintf(file, "Least Frequent: %s, Occurrences: %d\n", colorSummary.LeastFrequentColor, colorSummary.LeastFrequentCount)
-
-		// Optionally, print full color extraction details
-		if config.IncludeFullColorExtract {
-			if colorResults, ok := result.Results["ColorExtractor"]; ok {
-				for hex, count := range colorResults {
-					fmt.Fprintf(file, "File: %s, Quantizer: ColorExtractor, Color: %s, Occurrences: %d\n", result.FilePath, hex, count)
-				}
-			}
-		}
-
-		// Print the results for each quantizer
-		for _, quantizerName := range []string{"KMeansQuantizer", "MedianCutQuantizer", "AverageQuantizer"} {
-			if palette, ok := result.Results[quantizerName]; ok {
-				for hex, count := range palette {
-					fmt.Fprintf(file, "File: %s, Quantizer: %s, Color: %s, Occurrences: %d\n", result.FilePath, quantizerName, hex, count)
-				}
-			}
-		}
-	}
+	_, err := io.WriteString(w, sb.String())
+	return err
 }
 
 // formatResultsForFile formats the results for pretty file output
-func formatResultsForFile(result imageprocessor.ImageResult) string {
+func formatResultsForFile(result imageprocessor.ImageResult, opts Options) string {
 	var sb strings.Builder
 	if result.Err != nil {
 		sb.WriteString(fmt.Sprintf("Error processing file %s: %v\n", result.FilePath, result.Err))
@@ -159,24 +133,36 @@ func formatResultsForFile(result imageprocessor.ImageResult) string {
 	sb.WriteString(fmt.Sprintf("    - Least Frequent: %s, Occurrences: %d\n", colorSummary.LeastFrequentColor, colorSummary.LeastFrequentCount))
 
 	// Optionally, print full color extraction details
-	if config.IncludeFullColorExtract {
+	if opts.IncludeFullColorExtract {
 		if colorResults, ok := result.Results["ColorExtractor"]; ok {
-			for hex, count := range colorResults {
-				sb.WriteString(fmt.Sprintf("    - Color %s: %d occurrences\n", hex, count))
+			for _, swatch := range imageprocessor.SortedSwatches(colorResults) {
+				sb.WriteString(fmt.Sprintf("    - Color %s: %d occurrences\n", withName(opts.Namer, swatch.Hex), swatch.Count))
 			}
 		}
 	}
 
 	// Then print the results for each quantizer
-	for _, quantizerName := range []string{"KMeansQuantizer", "MedianCutQuantizer", "AverageQuantizer"} {
+	for _, quantizerName := range result.Quantizers {
+		if err, failed := result.Errors[quantizerName]; failed {
+			sb.WriteString(fmt.Sprintf("Results for Quantizer: %s\n", quantizerName))
+			sb.WriteString(fmt.Sprintf("    - Error: %v\n\n", err))
+			continue
+		}
 		if palette, ok := result.Results[quantizerName]; ok {
 			sb.WriteString(fmt.Sprintf("Results for Quantizer: %s\n", quantizerName))
-			for hex, count := range palette {
-				sb.WriteString(fmt.Sprintf("    - Color %s: %d occurrences\n", hex, count))
+			for _, swatch := range imageprocessor.SortedSwatches(palette) {
+				sb.WriteString(fmt.Sprintf("    - Color %s: %d occurrences\n", withName(opts.Namer, swatch.Hex), swatch.Count))
+			}
+			if metrics, ok := result.Metrics[quantizerName]; ok {
+				sb.WriteString(fmt.Sprintf("    - Quality: %s\n", FormatMetrics(metrics)))
 			}
 			sb.WriteString("\n")
 		}
 	}
 
+	if ranking := formatRanking(result); ranking != "" {
+		sb.WriteString(fmt.Sprintf("Ranking: %s\n", ranking))
+	}
+
 	return sb.String()
 }
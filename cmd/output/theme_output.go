@@ -0,0 +1,181 @@
+package output
+
+import (
+	"bufio"
+	"colorsage/palette"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// themeWriters maps terminal theme formats to their writers
+var themeWriters = map[string]func(w io.Writer, theme palette.Theme, name string) error{
+	"xresources":       writeXresources,
+	"alacritty":        writeAlacritty,
+	"kitty":            writeKitty,
+	"base16":           writeBase16,
+	"windows-terminal": writeWindowsTerminal,
+}
+
+// ThemeFormats returns the names of all supported terminal theme formats
+func ThemeFormats() []string {
+	names := make([]string, 0, len(themeWriters))
+	for name := range themeWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteTheme writes a terminal theme in the named format
+func WriteTheme(w io.Writer, format string, theme palette.Theme, name string) error {
+	writer, ok := themeWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown theme format %q. Supported formats: %s", format, strings.Join(ThemeFormats(), ", "))
+	}
+	return writer(w, theme, name)
+}
+
+// DisplayTheme prints the theme's colors as blocks on the terminal
+func DisplayTheme(w io.Writer, theme palette.Theme) {
+	fmt.Fprintf(w, "%s  background  %s %s  foreground  %s %s  cursor  %s\n",
+		BackgroundColor(theme.Background), Reset, BackgroundColor(theme.Foreground), Reset, BackgroundColor(theme.Cursor), Reset)
+	for row := 0; row < 2; row++ {
+		for i := row * 8; i < row*8+8; i++ {
+			fmt.Fprintf(w, "%s     %s", BackgroundColor(theme.ANSI[i]), Reset)
+		}
+		fmt.Fprintln(w)
+	}
+	// The same colors as text on the background, the way they appear in a terminal
+	r, g, b := hexRGB(theme.Background)
+	for row := 0; row < 2; row++ {
+		fmt.Fprintf(w, "\033[48;2;%d;%d;%dm", r, g, b)
+		for i := row * 8; i < row*8+8; i++ {
+			fr, fg, fb := hexRGB(theme.ANSI[i])
+			fmt.Fprintf(w, "\033[38;2;%d;%d;%dm Text", fr, fg, fb)
+		}
+		fmt.Fprintln(w, " "+Reset)
+	}
+}
+
+func hexRGB(hex string) (uint8, uint8, uint8) {
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		return 0, 0, 0
+	}
+	return c.RGB255()
+}
+
+func writeXresources(w io.Writer, theme palette.Theme, name string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "! %s\n", name)
+	fmt.Fprintf(bw, "*.background: %s\n", theme.Background)
+	fmt.Fprintf(bw, "*.foreground: %s\n", theme.Foreground)
+	fmt.Fprintf(bw, "*.cursorColor: %s\n", theme.Cursor)
+	for i, hex := range theme.ANSI {
+		fmt.Fprintf(bw, "*.color%d: %s\n", i, hex)
+	}
+	return bw.Flush()
+}
+
+func writeAlacritty(w io.Writer, theme palette.Theme, name string) error {
+	slots := []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n", name)
+	fmt.Fprintln(bw, "[colors.primary]")
+	fmt.Fprintf(bw, "background = %q\n", theme.Background)
+	fmt.Fprintf(bw, "foreground = %q\n\n", theme.Foreground)
+	fmt.Fprintln(bw, "[colors.cursor]")
+	fmt.Fprintf(bw, "text = %q\n", theme.Background)
+	fmt.Fprintf(bw, "cursor = %q\n\n", theme.Cursor)
+	fmt.Fprintln(bw, "[colors.selection]")
+	fmt.Fprintf(bw, "text = %q\n", "CellForeground")
+	fmt.Fprintf(bw, "background = %q\n", theme.Selection)
+	for _, section := range []struct {
+		name   string
+		offset int
+	}{{"normal", 0}, {"bright", 8}} {
+		fmt.Fprintf(bw, "\n[colors.%s]\n", section.name)
+		for i, slot := range slots {
+			fmt.Fprintf(bw, "%s = %q\n", slot, theme.ANSI[section.offset+i])
+		}
+	}
+	return bw.Flush()
+}
+
+func writeKitty(w io.Writer, theme palette.Theme, name string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n", name)
+	fmt.Fprintf(bw, "background %s\n", theme.Background)
+	fmt.Fprintf(bw, "foreground %s\n", theme.Foreground)
+	fmt.Fprintf(bw, "cursor %s\n", theme.Cursor)
+	fmt.Fprintf(bw, "cursor_text_color %s\n", theme.Background)
+	fmt.Fprintf(bw, "selection_background %s\n", theme.Selection)
+	fmt.Fprintf(bw, "selection_foreground %s\n", theme.Foreground)
+	for i, hex := range theme.ANSI {
+		fmt.Fprintf(bw, "color%d %s\n", i, hex)
+	}
+	return bw.Flush()
+}
+
+// writeBase16 writes the theme as a base16 scheme. Orange and brown have no ANSI slot, so they
+// are blended from red and yellow.
+func writeBase16(w io.Writer, theme palette.Theme, name string) error {
+	blend := func(a, b string, t float64) string {
+		ca, _ := colorful.Hex(a)
+		cb, _ := colorful.Hex(b)
+		return ca.BlendLab(cb, t).Clamped().Hex()
+	}
+	orange := blend(theme.ANSI[1], theme.ANSI[3], 0.5)
+	bases := [16]string{
+		theme.Background,
+		theme.ANSI[0],
+		theme.Selection,
+		theme.ANSI[8],
+		blend(theme.ANSI[8], theme.Foreground, 0.5),
+		theme.ANSI[7],
+		blend(theme.ANSI[7], theme.ANSI[15], 0.5),
+		theme.ANSI[15],
+		theme.ANSI[1],
+		orange,
+		theme.ANSI[3],
+		theme.ANSI[2],
+		theme.ANSI[6],
+		theme.ANSI[4],
+		theme.ANSI[5],
+		blend(orange, theme.Background, 0.4),
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "scheme: %q\n", name)
+	fmt.Fprintf(bw, "author: %q\n", "colorsage")
+	for i, hex := range bases {
+		fmt.Fprintf(bw, "base%02X: %q\n", i, strings.TrimPrefix(hex, "#"))
+	}
+	return bw.Flush()
+}
+
+func writeWindowsTerminal(w io.Writer, theme palette.Theme, name string) error {
+	slots := []string{"black", "red", "green", "yellow", "blue", "purple", "cyan", "white"}
+
+	scheme := map[string]string{
+		"name":                name,
+		"background":          theme.Background,
+		"foreground":          theme.Foreground,
+		"cursorColor":         theme.Cursor,
+		"selectionBackground": theme.Selection,
+	}
+	for i, slot := range slots {
+		scheme[slot] = theme.ANSI[i]
+		scheme["bright"+strings.ToUpper(slot[:1])+slot[1:]] = theme.ANSI[i+8]
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(scheme)
+}
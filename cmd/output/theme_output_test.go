@@ -0,0 +1,164 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/palette"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var testTheme = palette.Theme{
+	Background: "#101010",
+	Foreground: "#f0f0f0",
+	Cursor:     "#ff8800",
+	Selection:  "#303030",
+	ANSI: [16]string{
+		"#202020", "#cc3333", "#33cc33", "#cccc33", "#3333cc", "#cc33cc", "#33cccc", "#d0d0d0",
+		"#606060", "#ff5555", "#55ff55", "#ffff55", "#5555ff", "#ff55ff", "#55ffff", "#f0f0f0",
+	},
+}
+
+func writeTestTheme(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteTheme(&buf, format, testTheme, "Test Theme"); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWriteXresources(t *testing.T) {
+	got := strings.Split(strings.TrimSuffix(writeTestTheme(t, "xresources"), "\n"), "\n")
+	want := []string{
+		"! Test Theme",
+		"*.background: #101010",
+		"*.foreground: #f0f0f0",
+		"*.cursorColor: #ff8800",
+	}
+	for i, hex := range testTheme.ANSI {
+		want = append(want, fmt.Sprintf("*.color%d: %s", i, hex))
+	}
+	compareLines(t, "xresources", got, want)
+}
+
+func TestWriteKitty(t *testing.T) {
+	got := strings.Split(strings.TrimSuffix(writeTestTheme(t, "kitty"), "\n"), "\n")
+	want := []string{
+		"# Test Theme",
+		"background #101010",
+		"foreground #f0f0f0",
+		"cursor #ff8800",
+		"cursor_text_color #101010",
+		"selection_background #303030",
+		"selection_foreground #f0f0f0",
+	}
+	for i, hex := range testTheme.ANSI {
+		want = append(want, fmt.Sprintf("color%d %s", i, hex))
+	}
+	compareLines(t, "kitty", got, want)
+}
+
+func TestWriteAlacritty(t *testing.T) {
+	got := writeTestTheme(t, "alacritty")
+	want := `# Test Theme
+[colors.primary]
+background = "#101010"
+foreground = "#f0f0f0"
+
+[colors.cursor]
+text = "#101010"
+cursor = "#ff8800"
+
+[colors.selection]
+text = "CellForeground"
+background = "#303030"
+
+[colors.normal]
+black = "#202020"
+red = "#cc3333"
+green = "#33cc33"
+yellow = "#cccc33"
+blue = "#3333cc"
+magenta = "#cc33cc"
+cyan = "#33cccc"
+white = "#d0d0d0"
+
+[colors.bright]
+black = "#606060"
+red = "#ff5555"
+green = "#55ff55"
+yellow = "#ffff55"
+blue = "#5555ff"
+magenta = "#ff55ff"
+cyan = "#55ffff"
+white = "#f0f0f0"
+`
+	compareLines(t, "alacritty", strings.Split(got, "\n"), strings.Split(want, "\n"))
+}
+
+func TestWriteBase16(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeTestTheme(t, "base16"), "\n"), "\n")
+	if len(lines) != 18 {
+		t.Fatalf("base16 has %d lines, want 18:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	if lines[0] != `scheme: "Test Theme"` || lines[1] != `author: "colorsage"` {
+		t.Errorf("base16 header = %q", lines[:2])
+	}
+
+	bases := map[string]string{}
+	for _, line := range lines[2:] {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("base16 line %q is not key: value", line)
+		}
+		bases[key] = value
+	}
+	// The slots taken directly from the theme; the rest are blends
+	want := map[string]string{
+		"base00": "101010", "base01": "202020", "base02": "303030", "base03": "606060",
+		"base05": "d0d0d0", "base07": "f0f0f0", "base08": "cc3333", "base0A": "cccc33",
+		"base0B": "33cc33", "base0C": "33cccc", "base0D": "3333cc", "base0E": "cc33cc",
+	}
+	for key, hex := range want {
+		if got := bases[key]; got != `"`+hex+`"` {
+			t.Errorf("%s = %s, want %q", key, got, hex)
+		}
+	}
+	for _, key := range []string{"base04", "base06", "base09", "base0F"} {
+		if value := bases[key]; len(value) != 8 {
+			t.Errorf("%s = %s, want a quoted 6-digit hex", key, value)
+		}
+	}
+}
+
+func TestWriteWindowsTerminal(t *testing.T) {
+	var scheme map[string]string
+	if err := json.Unmarshal([]byte(writeTestTheme(t, "windows-terminal")), &scheme); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"name": "Test Theme", "background": "#101010", "foreground": "#f0f0f0",
+		"cursorColor": "#ff8800", "selectionBackground": "#303030",
+		"black": "#202020", "red": "#cc3333", "green": "#33cc33", "yellow": "#cccc33",
+		"blue": "#3333cc", "purple": "#cc33cc", "cyan": "#33cccc", "white": "#d0d0d0",
+		"brightBlack": "#606060", "brightRed": "#ff5555", "brightGreen": "#55ff55", "brightYellow": "#ffff55",
+		"brightBlue": "#5555ff", "brightPurple": "#ff55ff", "brightCyan": "#55ffff", "brightWhite": "#f0f0f0",
+	}
+	if len(scheme) != len(want) {
+		t.Errorf("scheme has %d keys, want %d: %v", len(scheme), len(want), scheme)
+	}
+	for key, value := range want {
+		if scheme[key] != value {
+			t.Errorf("%s = %q, want %q", key, scheme[key], value)
+		}
+	}
+}
+
+func TestWriteThemeUnknownFormat(t *testing.T) {
+	err := WriteTheme(&bytes.Buffer{}, "iterm", testTheme, "Test Theme")
+	if err == nil || !strings.Contains(err.Error(), "Supported formats: alacritty, base16, kitty, windows-terminal, xresources") {
+		t.Errorf("WriteTheme(iterm) error = %v", err)
+	}
+}
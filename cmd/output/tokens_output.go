@@ -0,0 +1,54 @@
+package output
+
+import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// dtcgToken is a single W3C Design Tokens Community Group color token
+type dtcgToken struct {
+	Type        string `json:"$type"`
+	Value       string `json:"$value"`
+	Description string `json:"$description,omitempty"`
+}
+
+// writeDesignTokens writes a palette in the W3C Design Tokens Community Group format. Semantic
+// roles (primary, secondary, background, surface, accent) are inferred from coverage and contrast
+// and written next to a "palette" group holding every color in order of frequency.
+func writeDesignTokens(w io.Writer, colors map[string]int, opts PaletteOptions) error {
+	total := imageprocessor.TotalCount(colors)
+
+	paletteGroup := make(map[string]dtcgToken)
+	for i, swatch := range imageprocessor.SortedSwatches(colors) {
+		token := dtcgToken{Type: "color", Value: swatch.Hex}
+		if total > 0 {
+			token.Description = fmt.Sprintf("%.2f%% coverage", float64(swatch.Count)*100/float64(total))
+		}
+		paletteGroup[fmt.Sprintf("%d", i+1)] = token
+	}
+
+	colorGroup := map[string]interface{}{
+		"palette": paletteGroup,
+	}
+	for _, semantic := range palette.InferSemanticColors(colors) {
+		token := dtcgToken{Type: "color", Value: semantic.Hex}
+		if semantic.Derived {
+			token.Description = "derived, not present in the palette"
+		}
+		colorGroup[semantic.Role] = token
+	}
+
+	document := map[string]interface{}{
+		"color": colorGroup,
+	}
+	if opts.Name != "" {
+		document["$description"] = opts.Name
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
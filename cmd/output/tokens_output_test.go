@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"colorsage/palette"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestWriteDesignTokens(t *testing.T) {
+	colors := map[string]int{"#ffffff": 60, "#1f77b4": 20, "#ff7f0e": 10, "#f0f0f0": 5, "#d62728": 5}
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "tokens", colors, PaletteOptions{Name: "site.png – KMeans"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Description string                     `json:"$description"`
+		Color       map[string]json.RawMessage `json:"color"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.Description != "site.png – KMeans" {
+		t.Errorf("$description = %q, want the palette name", document.Description)
+	}
+
+	var paletteGroup map[string]dtcgToken
+	if err := json.Unmarshal(document.Color["palette"], &paletteGroup); err != nil {
+		t.Fatal(err)
+	}
+	wantPalette := map[string]dtcgToken{
+		"1": {Type: "color", Value: "#ffffff", Description: "60.00% coverage"},
+		"2": {Type: "color", Value: "#1f77b4", Description: "20.00% coverage"},
+		"3": {Type: "color", Value: "#ff7f0e", Description: "10.00% coverage"},
+		"4": {Type: "color", Value: "#d62728", Description: "5.00% coverage"},
+		"5": {Type: "color", Value: "#f0f0f0", Description: "5.00% coverage"},
+	}
+	if !reflect.DeepEqual(paletteGroup, wantPalette) {
+		t.Errorf("palette group = %+v, want %+v", paletteGroup, wantPalette)
+	}
+
+	// Every semantic role is a color token next to the palette group
+	if len(document.Color) != len(palette.SemanticRoles)+1 {
+		t.Errorf("color group has %d entries, want the palette and %d roles", len(document.Color), len(palette.SemanticRoles))
+	}
+	for _, semantic := range palette.InferSemanticColors(colors) {
+		var token dtcgToken
+		if err := json.Unmarshal(document.Color[semantic.Role], &token); err != nil {
+			t.Fatalf("%s: %v", semantic.Role, err)
+		}
+		if want := (dtcgToken{Type: "color", Value: semantic.Hex}); token != want {
+			t.Errorf("%s = %+v, want %+v", semantic.Role, token, want)
+		}
+	}
+}
+
+func TestWriteDesignTokensDerived(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePalette(&buf, "tokens", map[string]int{"#1f77b4": 1}, PaletteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := document["$description"]; ok {
+		t.Error("unnamed palette has a $description")
+	}
+	var accent dtcgToken
+	if err := json.Unmarshal(document["color"]["accent"], &accent); err != nil {
+		t.Fatal(err)
+	}
+	if accent.Description != "derived, not present in the palette" {
+		t.Errorf("derived accent = %+v, want it described as derived", accent)
+	}
+}
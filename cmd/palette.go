@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"colorsage/cmd/output"
+	"colorsage/imageprocessor"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// paletteQuantizerName is the result key under which a loaded palette is reported
+const paletteQuantizerName = "Palette"
+
+var paletteCmd = &cobra.Command{
+	Use:   "palette [files.gpl...]",
+	Short: "Load existing GIMP palettes and summarize or re-render them.",
+	Long: `Load existing GIMP Palette (.gpl) files, as written by GIMP, Inkscape, Krita
+or colorsage --palette-format gpl, and run them through the regular output:
+the summary table, the results file and, with --palette-format, palette files.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateOutputFlags(cmd); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		results := make([]imageprocessor.ImageResult, len(args))
+		for i, filePath := range args {
+			results[i] = loadPalette(filePath)
+		}
+
+		writeOutputs(cmd, results)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(paletteCmd)
+}
+
+// loadPalette reads a GIMP palette into a result whose extracted colors and only palette are the palette's swatches
+func loadPalette(filePath string) imageprocessor.ImageResult {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return imageprocessor.ImageResult{FilePath: filePath, Err: err}
+	}
+	defer file.Close()
+
+	gpl, err := output.ReadGPL(file)
+	if err != nil {
+		return imageprocessor.ImageResult{FilePath: filePath, Err: err}
+	}
+
+	colors := gpl.Colors()
+	return imageprocessor.ImageResult{
+		FilePath: filePath,
+		Results: map[string]map[string]int{
+			"ColorExtractor":     colors,
+			paletteQuantizerName: colors,
+		},
+		Quantizers: []string{paletteQuantizerName},
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remapFormat      string
+	remapColors      int
+	ditherMethod     string
+	ditherSerpentine bool
+	ditherStrength   float64
+	ditherSpace      string
+)
+
+var remapCmd = &cobra.Command{
+	Use:   "remap [images...]",
+	Short: "Redraw images using only the colors of their quantized palettes.",
+	Long: `Redraw images using only the colors of their quantized palettes.
+
+Every pixel is replaced by the palette color nearest to it in Lab space, and
+the result is written as an indexed PNG or GIF, one per selected quantizer.
+Transparency is kept: PNG keeps partial alpha in as many levels as the
+palette leaves room for, GIF only full transparency.
+Comparing the images of --all shows how faithful each quantizer's palette is.
+
+With --dither, the quantization error is diffused to neighbouring pixels
+(floyd-steinberg, atkinson, sierra) or broken up by an ordered Bayer pattern
+(bayer2, bayer4, bayer8), which avoids banding in low-color images. The error
+is measured in linear RGB or Lab, chosen with --dither-space.
+
+Files are named like palette files, with a .remap extension, and follow
+--palette-dir, --palette-name and --generate-palette-images-in-current-dir.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !slices.Contains(output.RemapFormats(), remapFormat) {
+			fmt.Printf("invalid remap format: %s. Supported formats: %s\n", remapFormat, strings.Join(output.RemapFormats(), ", "))
+			return
+		}
+		if remapColors < 1 || remapColors > palette.MaxRemapColors {
+			fmt.Printf("invalid number of colors: %d. It must be between 1 and %d\n", remapColors, palette.MaxRemapColors)
+			return
+		}
+		if !slices.Contains(palette.DitherMethods, ditherMethod) {
+			fmt.Printf("invalid dither method: %s. Supported methods: %s\n", ditherMethod, strings.Join(palette.DitherMethods, ", "))
+			return
+		}
+		if !slices.Contains(palette.DitherSpaces, ditherSpace) {
+			fmt.Printf("invalid dither space: %s. Supported spaces: %s\n", ditherSpace, strings.Join(palette.DitherSpaces, ", "))
+			return
+		}
+		if ditherStrength < 0 || ditherStrength > 1 {
+			fmt.Printf("invalid dither strength: %g. It must be between 0 and 1\n", ditherStrength)
+			return
+		}
+		namer, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		for _, filePath := range args {
+			source := decodeSource(filePath)
+			if source == nil {
+				fmt.Printf("Error processing file %s: can't decode image\n", filePath)
+				continue
+			}
+			result, err := colorsage.ExtractImage(cmd.Context(), source, colorsage.Options{
+				Quantizers: quantizers,
+				NumColors:  remapColors,
+			})
+			if err != nil {
+				fmt.Printf("Error processing file %s: %v\n", filePath, err)
+				continue
+			}
+
+			for _, quantizerName := range result.Quantizers {
+				if err, failed := result.Errors[quantizerName]; failed {
+					fmt.Printf("Error running %s on %s: %v\n", quantizerName, filePath, err)
+					continue
+				}
+				remapped, err := palette.Dither(source, result.Results[quantizerName], palette.DitherOptions{
+					Method:     ditherMethod,
+					Serpentine: ditherSerpentine,
+					Strength:   ditherStrength,
+					Space:      ditherSpace,
+				})
+				if err != nil {
+					fmt.Printf("Error remapping %s with %s: %v\n", filePath, quantizerName, err)
+					continue
+				}
+				outPath, err := namer.Name(filePath, quantizerName, ".remap."+remapFormat)
+				if err == nil {
+					err = output.WriteRemappedImage(outPath, remapFormat, remapped)
+				}
+				if err != nil {
+					fmt.Printf("Error writing remapped image for %s: %v\n", filePath, err)
+					continue
+				}
+				fmt.Println(outPath)
+			}
+		}
+	},
+}
+
+func init() {
+	remapCmd.Flags().StringVar(&remapFormat, "remap-format", "png", "Format of the remapped images ("+strings.Join(output.RemapFormats(), ", ")+").")
+	remapCmd.Flags().IntVar(&remapColors, "colors", imageprocessor.DefaultNumColors, "Number of palette colors to remap to.")
+	remapCmd.Flags().StringVar(&ditherMethod, "dither", palette.DitherNone, "Dithering method ("+strings.Join(palette.DitherMethods, ", ")+").")
+	remapCmd.Flags().BoolVar(&ditherSerpentine, "serpentine", false, "Alternate the scan direction on every row of error diffusion dithering.")
+	remapCmd.Flags().Float64Var(&ditherStrength, "dither-strength", 1, "Fraction of the quantization error or ordered threshold applied, from 0 to 1.")
+	remapCmd.Flags().StringVar(&ditherSpace, "dither-space", palette.SpaceLinear, "Color space the dithering error is computed in ("+strings.Join(palette.DitherSpaces, ", ")+").")
+	rootCmd.AddCommand(remapCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rolesFormat string
+	rolesColors int
+)
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles [images...]",
+	Short: "Assign vibrant and muted UI roles to an image's palette.",
+	Long: `Assign vibrant and muted UI roles to an image's palette, like Android's Palette.
+
+Besides the dominant color, the Vibrant, LightVibrant, DarkVibrant, Muted,
+LightMuted and DarkMuted roles each take the palette color whose HSL
+saturation and lightness are closest to the role's target, weighted by how
+much of the image it covers. Every role comes with title and body text colors:
+white or black at the lowest opacity reaching 3:1 and 4.5:1 contrast.
+
+The report is printed to stdout, or written to the file given with --output.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !slices.Contains(output.RolesFormats(), rolesFormat) {
+			fmt.Printf("invalid roles format: %s. Supported formats: %s\n", rolesFormat, strings.Join(output.RolesFormats(), ", "))
+			return
+		}
+
+		results := colorsage.ExtractFiles(cmd.Context(), args, colorsage.Options{
+			Quantizers: quantizers,
+			NumColors:  rolesColors,
+			Sequential: config.Sequential,
+		})
+
+		var reports []output.RolesReport
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing file %s: %v\n", result.FilePath, result.Err)
+				continue
+			}
+			for _, quantizerName := range result.Quantizers {
+				if err, failed := result.Errors[quantizerName]; failed {
+					fmt.Fprintf(os.Stderr, "Error running %s on %s: %v\n", quantizerName, result.FilePath, err)
+					continue
+				}
+				reports = append(reports, output.RolesReport{
+					File:      result.FilePath,
+					Quantizer: quantizerName,
+					Swatches:  palette.ClassifyVibrant(result.Results[quantizerName]),
+				})
+			}
+		}
+
+		write := func(w io.Writer) error {
+			return output.WriteRolesReports(w, rolesFormat, reports)
+		}
+		if cmd.Flags().Changed("output") {
+			err = output.WriteFileAtomic(config.OutputFile, false, write)
+		} else {
+			err = write(os.Stdout)
+		}
+		if err != nil {
+			fmt.Println("Error writing roles:", err)
+		}
+	},
+}
+
+func init() {
+	rolesCmd.Flags().StringVar(&rolesFormat, "roles-format", "table", "Roles report format ("+strings.Join(output.RolesFormats(), ", ")+").")
+	// Android's Palette works on up to 16 colors; more candidates fill more roles
+	rolesCmd.Flags().IntVar(&rolesColors, "colors", 16, "Number of palette colors to extract.")
+	rootCmd.AddCommand(rolesCmd)
+}
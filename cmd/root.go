@@ -2,11 +2,20 @@
 package cmd
 
 import (
+	"colorsage"
 	"colorsage/cmd/output"
 	"colorsage/config"
 	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"context"
 	"fmt"
+	"image"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -14,94 +23,308 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "colorsage [files...]",
 	Short: "Process images and extract color palettes using various quantization algorithms.",
-	Long: `colorsage is a tool for analyzing images and extracting their color palettes.
-It supports multiple quantization algorithms to generate a reduced color palette.
-
-By default, the tool runs the fastest quantizer (KMeansQuantizer).
-You can use the --all flag to run all available quantizers (KMeans, MedianCut, and Average), 
-or specify a particular quantizer using the --quantizer flag.`,
-	Args: cobra.MinimumNArgs(1),
+	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		config.FilePaths = args // Capture the file paths from command-line arguments
 
-		processors := []imageprocessor.ImageProcessor{
-			&imageprocessor.ColorExtractor{},
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
 
-		var quantizers []imageprocessor.Quantizer
-
-		// Determine which quantizers to run based on the command-line arguments
-		if config.All {
-			quantizers = append(quantizers, imageprocessor.KMeansQuantizer{})
-			quantizers = append(quantizers, imageprocessor.MedianCutQuantizer{})
-			quantizers = append(quantizers, imageprocessor.AverageQuantizer{})
-		} else if config.QuantizerType != "" {
-			// Run only the specified quantizer
-			quantizer, err := getQuantizerByName(config.QuantizerType)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			quantizers = append(quantizers, quantizer)
-		} else if config.Fast {
-			// Run only the fastest quantizer if --fast is specified
-			quantizers = append(quantizers, imageprocessor.KMeansQuantizer{})
-		} else {
-			// Run only the fastest quantizer if neither --all nor --quantizer nor --fast are specified
-			quantizers = append(quantizers, imageprocessor.KMeansQuantizer{})
+		if err := validateOutputFlags(cmd); err != nil {
+			fmt.Println(err)
+			return
 		}
 
-		// Process the images using the selected quantizers
-		results := imageprocessor.ProcessPipeline(config.FilePaths, processors, quantizers, config.Sequential)
-
-		// Generate palette images if the flag is set
-		if config.GeneratePaletteImagesInCurrentDir {
-			for _, result := range results {
-				for quantizerName, palette := range result.Results {
-					filePath := output.GeneratePaletteFilename(result.FilePath, quantizerName)
-					err := output.GeneratePaletteImage(palette, filePath)
-					if err != nil {
-						fmt.Printf("Error generating palette image for %s: %v\n", result.FilePath, err)
-					}
-				}
-			}
+		// Status messages go to stderr so they don't corrupt machine-readable output
+		if config.Sequential {
+			fmt.Fprintln(os.Stderr, output.Yellow+"Running in sequential mode..."+output.Reset)
+		} else {
+			fmt.Fprintln(os.Stderr, output.Yellow+"Running in parallel mode..."+output.Reset)
 		}
 
-		// Display results in a table format
-		output.DisplayResults(results)
+		// Process the images using the selected quantizers
+		results := colorsage.ExtractFiles(cmd.Context(), config.FilePaths, colorsage.Options{
+			Quantizers: quantizers,
+			Sequential: config.Sequential,
+			Metrics:    config.Metrics,
+			KeepImage:  config.PaletteCard || showsSources(),
+		})
 
-		// Write all quantizer outputs to a file
-		output.WriteResultsToFile("colors.txt", results)
+		writeOutputs(cmd, results)
 	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Interrupting stops running external quantizers instead of leaving them behind
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
 func init() {
+	rootCmd.Long = longDescription()
+	defaultHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		// Only the help lists external quantizers, so only it pays for scanning $PATH upfront
+		if len(imageprocessor.DiscoverPlugins()) > 0 {
+			rootCmd.Long = longDescription()
+			rootCmd.PersistentFlags().Lookup("quantizer").Usage = quantizerUsage()
+			rootCmd.PersistentFlags().Lookup("all").Usage = allUsage()
+		}
+		defaultHelp(cmd, args)
+	})
+
 	rootCmd.PersistentFlags().BoolVarP(&config.Sequential, "sequential", "s", false, "Run the image processing pipeline sequentially (default: parallel)")
-	rootCmd.PersistentFlags().StringVarP(&config.QuantizerType, "quantizer", "q", "", "Specify which quantizer to use (kmeans, mediancut, average). If not specified, the fastest quantizer is used.")
+	rootCmd.PersistentFlags().StringVarP(&config.QuantizerType, "quantizer", "q", "", quantizerUsage())
 	rootCmd.PersistentFlags().BoolVar(&config.Fast, "fast", false, "Run only the fastest quantizer (default: KMeansQuantizer). This flag overrides running all quantizers.")
-	rootCmd.PersistentFlags().BoolVar(&config.All, "all", false, "Run all available quantizers (KMeans, MedianCut, Average).")
+	rootCmd.PersistentFlags().BoolVar(&config.All, "all", false, allUsage())
 	rootCmd.PersistentFlags().BoolVar(&config.RawOutput, "raw", false, "Output raw results without UI elements, suitable for piping or redirection.")
+	rootCmd.PersistentFlags().StringVarP(&config.Format, "format", "f", "", "Output format ("+strings.Join(output.Formats(), ", ")+"). Defaults to table on a terminal and raw otherwise.")
+	rootCmd.PersistentFlags().StringVarP(&config.OutputFile, "output", "o", "colors.txt", "File to write the results to, colors.html by default with --format html. The format is inferred from the extension (.json, .ndjson, .jsonl, .csv, .tsv, .html), falling back to --format or text.")
+	rootCmd.PersistentFlags().BoolVar(&config.NoOutputFile, "no-output-file", false, "Don't write a results file.")
+	rootCmd.PersistentFlags().StringVar(&config.OutputMode, "output-mode", "", "How to write the results file ("+strings.Join(output.FileModes, ", ")+"). Defaults to overwrite with --output and to timestamp otherwise, so the default colors.txt is never replaced.")
 	rootCmd.PersistentFlags().BoolVar(&config.IncludeFullColorExtract, "full", false, "Include full color extraction details in the output.")
+	rootCmd.PersistentFlags().BoolVar(&config.Metrics, "metrics", false, "Remap each image to every palette and report MSE, PSNR, SSIM and CIEDE2000 error, ranking the quantizers.")
+	rootCmd.PersistentFlags().StringVar(&config.ColorNames, "names", "", "Annotate swatches with the nearest color name from a dictionary ("+strings.Join(palette.Dictionaries, ", ")+").")
+	rootCmd.PersistentFlags().StringVar(&config.NameMatch, "name-match", palette.MatchCIEDE2000, "Color difference used to find the nearest name ("+strings.Join(palette.MatchMethods, ", ")+").")
+	rootCmd.PersistentFlags().StringSliceVar(&config.PaletteFormats, "palette-format", []string{"png"}, "Comma-separated palette file formats to generate ("+strings.Join(output.PaletteFormats(), ", ")+"). Setting it enables palette generation.")
+	rootCmd.PersistentFlags().BoolVar(&config.PaletteLab, "palette-lab", false, "Store colors as Lab instead of RGB in palette formats that support it (ase, aco).")
+	rootCmd.PersistentFlags().StringVar(&config.PaletteLayout, "palette-layout", output.LayoutHorizontal, "Palette image layout ("+strings.Join(output.Layouts, ", ")+").")
+	rootCmd.PersistentFlags().StringVar(&config.PaletteSort, "palette-sort", palette.SortFrequency, "Swatch order in palette images ("+strings.Join(palette.SortOrders, ", ")+").")
+	rootCmd.PersistentFlags().BoolVar(&config.PaletteProportional, "palette-proportional", false, "Size palette image swatches by their coverage.")
+	rootCmd.PersistentFlags().BoolVar(&config.PaletteLabels, "palette-labels", false, "Draw hex labels on palette image swatches.")
+	rootCmd.PersistentFlags().IntVar(&config.PaletteBlockSize, "palette-block-size", 50, "Edge length of palette image swatches in pixels.")
+	rootCmd.PersistentFlags().BoolVar(&config.PaletteDonut, "palette-donut", false, "Add a donut chart of coverage to SVG palettes.")
+	rootCmd.PersistentFlags().BoolVar(&config.PaletteCard, "palette-card", false, "Generate a card with a thumbnail of the source image above its palette.")
 	rootCmd.PersistentFlags().BoolVar(&config.GeneratePaletteImagesInCurrentDir, "generate-palette-images-in-current-dir", false, "Generate palette images in the current directory instead of alongside the image files.")
+	rootCmd.PersistentFlags().StringVar(&config.PaletteDir, "palette-dir", "", "Directory to generate palette files in, created if needed. Overrides --generate-palette-images-in-current-dir.")
+	rootCmd.PersistentFlags().StringVar(&config.PaletteNameTemplate, "palette-name", output.DefaultPaletteNameTemplate, "Go template for palette file paths, with fields .Dir, .SourceDir, .Name, .Base, .Quantizer and .Ext.")
+}
+
+// validateOutputFlags checks the flags shared by every command that produces results and fills in
+// the defaults that depend on other flags
+func validateOutputFlags(cmd *cobra.Command) error {
+	if config.Format == htmlFormat && !cmd.Flags().Changed("output") {
+		// An HTML report belongs in an .html file rather than the default colors.txt
+		config.OutputFile = strings.TrimSuffix(config.OutputFile, filepath.Ext(config.OutputFile)) + ".html"
+	}
+	if config.OutputMode == "" {
+		// Only a file the user named is replaced; repeated default runs keep their earlier results
+		config.OutputMode = output.FileModeOverwrite
+		if !cmd.Flags().Changed("output") {
+			config.OutputMode = output.FileModeTimestamp
+		}
+	}
+	if config.Format != "" && !output.IsFormat(config.Format) {
+		return fmt.Errorf("invalid output format: %s. Supported formats: %s", config.Format, strings.Join(output.Formats(), ", "))
+	}
+	if !slices.Contains(output.FileModes, config.OutputMode) {
+		return fmt.Errorf("invalid output mode: %s. Supported modes: %s", config.OutputMode, strings.Join(output.FileModes, ", "))
+	}
+	if !config.NoOutputFile && config.OutputMode == output.FileModeAppend {
+		format := output.ResultsFileFormat(config.OutputFile, output.Options{Format: config.Format, Raw: config.RawOutput})
+		if !slices.Contains(output.AppendableFormats, format) {
+			return fmt.Errorf("--output-mode %s can't be used with %s output. Appendable formats: %s", output.FileModeAppend, format, strings.Join(output.AppendableFormats, ", "))
+		}
+	}
+	for _, format := range config.PaletteFormats {
+		if _, err := output.PaletteExtension(format); err != nil {
+			return err
+		}
+	}
+	if _, err := colorNamer(); err != nil {
+		return err
+	}
+	if _, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir); err != nil {
+		return err
+	}
+	if !slices.Contains(output.Layouts, config.PaletteLayout) {
+		return fmt.Errorf("invalid palette layout: %s. Supported layouts: %s", config.PaletteLayout, strings.Join(output.Layouts, ", "))
+	}
+	if !slices.Contains(palette.SortOrders, config.PaletteSort) {
+		return fmt.Errorf("invalid palette order: %s. Supported orders: %s", config.PaletteSort, strings.Join(palette.SortOrders, ", "))
+	}
+	return nil
+}
+
+// colorNamer returns the namer selected with --names, or nil if swatches aren't named
+func colorNamer() (*palette.ColorNamer, error) {
+	if config.ColorNames == "" {
+		return nil, nil
+	}
+	return palette.NewColorNamer(config.ColorNames, config.NameMatch)
 }
 
-// getQuantizerByName returns the quantizer instance based on the provided name
-func getQuantizerByName(name string) (imageprocessor.Quantizer, error) {
-	switch name {
-	case "kmeans":
-		return imageprocessor.KMeansQuantizer{}, nil
-	case "mediancut":
-		return imageprocessor.MedianCutQuantizer{}, nil
-	case "average":
-		return imageprocessor.AverageQuantizer{}, nil
-	default:
-		return nil, fmt.Errorf("invalid quantizer type: %s. Supported types: kmeans, mediancut, average", name)
+// writeOutputs generates palette files, displays the results and writes the results file
+func writeOutputs(cmd *cobra.Command, results []imageprocessor.ImageResult) {
+	namer, err := colorNamer()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Generate palette files if requested
+	generatePalettes := config.GeneratePaletteImagesInCurrentDir || config.PaletteDir != "" || cmd.Flags().Changed("palette-format")
+	if generatePalettes || config.PaletteCard {
+		paletteNamer, err := output.NewPaletteNamer(config.PaletteDir, config.PaletteNameTemplate, config.GeneratePaletteImagesInCurrentDir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, result := range results {
+			var source image.Image
+			if config.PaletteCard {
+				source = result.Image
+			}
+
+			quantizerNames := result.Quantizers
+			if config.IncludeFullColorExtract {
+				quantizerNames = append([]string{"ColorExtractor"}, quantizerNames...)
+			}
+			for _, quantizerName := range quantizerNames {
+				colors, ok := result.Results[quantizerName]
+				if !ok {
+					continue
+				}
+				paletteOptions := output.PaletteOptions{
+					Name:         filepath.Base(result.FilePath) + " – " + strings.TrimSuffix(quantizerName, "Quantizer"),
+					Lab:          config.PaletteLab,
+					Layout:       config.PaletteLayout,
+					Sort:         config.PaletteSort,
+					Proportional: config.PaletteProportional,
+					Labels:       config.PaletteLabels,
+					BlockSize:    config.PaletteBlockSize,
+					Donut:        config.PaletteDonut,
+					Namer:        namer,
+				}
+				if generatePalettes {
+					for _, format := range config.PaletteFormats {
+						extension, _ := output.PaletteExtension(format)
+						filePath, err := paletteNamer.Name(result.FilePath, quantizerName, extension)
+						if err != nil {
+							fmt.Printf("Error generating %s palette for %s: %v\n", format, result.FilePath, err)
+							continue
+						}
+						if err := output.WritePaletteFile(filePath, format, colors, paletteOptions); err != nil {
+							fmt.Printf("Error generating %s palette for %s: %v\n", format, result.FilePath, err)
+						}
+					}
+				}
+				if source != nil {
+					filePath, err := paletteNamer.Name(result.FilePath, quantizerName, ".card.png")
+					if err != nil {
+						fmt.Printf("Error generating palette card for %s: %v\n", result.FilePath, err)
+						continue
+					}
+					if err := output.GeneratePaletteCard(source, colors, filePath, paletteOptions); err != nil {
+						fmt.Printf("Error generating palette card for %s: %v\n", result.FilePath, err)
+					}
+				}
+			}
+		}
+	}
+
+	outputOptions := output.Options{
+		Format:                  config.Format,
+		Raw:                     config.RawOutput,
+		IncludeFullColorExtract: config.IncludeFullColorExtract,
+		Namer:                   namer,
+	}
+
+	// Display results in a table format, unless they make up a document that is written to a file
+	if !(config.Format == htmlFormat && !config.NoOutputFile) {
+		output.DisplayResults(results, outputOptions)
+	}
+
+	// Write all quantizer outputs to a file
+	if !config.NoOutputFile {
+		path, err := output.WriteResultsToFile(config.OutputFile, config.OutputMode, results, outputOptions)
+		if err != nil {
+			fmt.Printf("Error writing results file %s: %v\n", path, err)
+		}
+	}
+}
+
+// htmlFormat is the output format of HTML reports, which embed thumbnails of the sources
+const htmlFormat = "html"
+
+// showsSources reports whether the results are written as an HTML report
+func showsSources() bool {
+	if config.Format == htmlFormat {
+		return true
+	}
+	return !config.NoOutputFile && output.FormatForPath(config.OutputFile) == htmlFormat
+}
+
+// decodeSource decodes an input image again for outputs that show it, returning nil if it can't be read
+func decodeSource(filePath string) image.Image {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// longDescription builds the help text, listing the quantizers known to the registry
+func longDescription() string {
+	return `colorsage is a tool for analyzing images and extracting their color palettes.
+It supports multiple quantization algorithms to generate a reduced color palette.
+
+By default, the tool runs the fastest quantizer (KMeansQuantizer).
+You can use the --all flag to run all available quantizers, or specify one or
+more quantizers with options using the --quantizer flag, for example:
+
+  colorsage --quantizer kmeans:iterations=30,mediancut image.png
+
+External quantizers are picked up from executables on $PATH named
+` + imageprocessor.PluginPrefix + `<name>. They receive the color histogram as
+JSON on stdin and answer with a JSON palette on stdout.
+
+Available quantizers:
+` + imageprocessor.QuantizerHelp()
+}
+
+// quantizerUsage describes the --quantizer flag, listing the registered quantizers
+func quantizerUsage() string {
+	return "Comma-separated quantizers to use, with optional name:key=value options (e.g. kmeans:iterations=30,mediancut). Available: " + strings.Join(imageprocessor.QuantizerNames(), ", ") + ". If not specified, the fastest quantizer is used."
+}
+
+// allUsage describes the --all flag, listing the registered quantizers
+func allUsage() string {
+	return "Run all available quantizers (" + strings.Join(imageprocessor.QuantizerNames(), ", ") + ")."
+}
+
+// selectQuantizers determines which quantizers to run based on the command-line arguments
+func selectQuantizers() ([]imageprocessor.Quantizer, error) {
+	if config.All {
+		imageprocessor.DiscoverPlugins()
+		return imageprocessor.AllQuantizers()
+	}
+	if config.QuantizerType != "" {
+		// Run only the specified quantizers, looking for external ones only if a name is unknown
+		quantizers, err := imageprocessor.ParseQuantizerSpec(config.QuantizerType)
+		if err != nil && len(imageprocessor.DiscoverPlugins()) > 0 {
+			return imageprocessor.ParseQuantizerSpec(config.QuantizerType)
+		}
+		return quantizers, err
+	}
+	// Run only the fastest quantizer if neither --all nor --quantizer are specified, or if --fast is
+	quantizer, err := imageprocessor.NewQuantizer("kmeans")
+	if err != nil {
+		return nil, err
 	}
+	return []imageprocessor.Quantizer{quantizer}, nil
 }
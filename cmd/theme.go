@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"colorsage"
+	"colorsage/cmd/output"
+	"colorsage/config"
+	"colorsage/palette"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	themeFormat      string
+	themeLight       bool
+	themeMinContrast float64
+	themeColors      int
+	themePreview     bool
+)
+
+var themeCmd = &cobra.Command{
+	Use:   "theme [image]",
+	Short: "Generate a terminal color theme from an image's palette.",
+	Long: `Generate a terminal color theme from an image's palette.
+
+The palette of the first selected quantizer is mapped onto the 16 ANSI colors
+plus background, foreground and cursor. Palette colors close to the hue of an
+ANSI slot (red, green, yellow, blue, magenta, cyan) are used for it; other
+slots get the target hue with the palette's typical saturation. Every ANSI
+color is adjusted to reach --min-contrast against the background.
+
+The theme is printed to stdout, or written to the file given with --output.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantizers, err := selectQuantizers()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		result := colorsage.ExtractFile(cmd.Context(), args[0], colorsage.Options{
+			Quantizers: quantizers[:1],
+			NumColors:  themeColors,
+		})
+		if result.Err != nil {
+			fmt.Printf("Error processing file %s: %v\n", args[0], result.Err)
+			return
+		}
+		quantizerName := result.Quantizers[0]
+		if err, failed := result.Errors[quantizerName]; failed {
+			fmt.Printf("Error running %s on %s: %v\n", quantizerName, args[0], err)
+			return
+		}
+
+		theme := palette.GenerateTheme(result.Results[quantizerName], palette.ThemeOptions{
+			Light:       themeLight,
+			MinContrast: themeMinContrast,
+		})
+		name := "colorsage " + strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+
+		write := func(w io.Writer) error {
+			return output.WriteTheme(w, themeFormat, theme, name)
+		}
+		if cmd.Flags().Changed("output") {
+			err = output.WriteFileAtomic(config.OutputFile, false, write)
+		} else {
+			err = write(os.Stdout)
+		}
+		if err != nil {
+			fmt.Println("Error writing theme:", err)
+			return
+		}
+
+		if themePreview {
+			output.DisplayTheme(os.Stderr, theme)
+		}
+	},
+}
+
+func init() {
+	themeCmd.Flags().StringVar(&themeFormat, "theme-format", "xresources", "Theme format ("+strings.Join(output.ThemeFormats(), ", ")+").")
+	themeCmd.Flags().BoolVar(&themeLight, "light", false, "Generate a light theme instead of a dark one.")
+	themeCmd.Flags().Float64Var(&themeMinContrast, "min-contrast", 4.5, "Minimum WCAG contrast ratio of the ANSI colors against the background.")
+	themeCmd.Flags().IntVar(&themeColors, "colors", 8, "Number of palette colors to extract for the theme.")
+	themeCmd.Flags().BoolVar(&themePreview, "preview", false, "Print a preview of the theme to stderr.")
+	rootCmd.AddCommand(themeCmd)
+}
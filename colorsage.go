@@ -0,0 +1,171 @@
+// Package colorsage extracts color palettes from images.
+//
+// It is the library behind the colorsage command: every call is configured
+// through Options and carries no global state, so it is safe to use from
+// concurrent goroutines in long-running services.
+package colorsage
+
+import (
+	"colorsage/imageprocessor"
+	"colorsage/palette"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Options configures a palette extraction
+type Options struct {
+	// Quantizers to run on the extracted colors. If empty, the default
+	// k-means quantizer is used.
+	Quantizers []imageprocessor.Quantizer
+
+	// NumColors is the palette size each quantizer reduces to. If zero,
+	// imageprocessor.DefaultNumColors is used.
+	NumColors int
+
+	// Sequential processes files one after another in ExtractFiles instead
+	// of in parallel.
+	Sequential bool
+
+	// Metrics remaps the image to each quantizer's palette and measures the
+	// result against the original in Result.Metrics.
+	Metrics bool
+
+	// KeepImage keeps the decoded image in Result.Image, for callers that
+	// go on to show it, instead of decoding the file again.
+	KeepImage bool
+}
+
+// Result holds the extracted color histogram and the palette of each quantizer.
+// Results["ColorExtractor"] is the full histogram; Quantizers lists the other
+// keys of Results in the order the quantizers ran.
+type Result = imageprocessor.ImageResult
+
+// Extract decodes an image from r and extracts its palette
+func Extract(ctx context.Context, r io.Reader, opts Options) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	start := time.Now()
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return Result{}, err
+	}
+	decodeTime := time.Since(start)
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	result, err := ExtractImage(ctx, img, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Timings["decode"] = decodeTime
+	return result, nil
+}
+
+// ExtractImage extracts the palette of an already decoded image. Cancelling ctx stops
+// quantizers that support it, such as external plugins.
+func ExtractImage(ctx context.Context, img image.Image, opts Options) (Result, error) {
+	quantizers, err := opts.quantizers()
+	if err != nil {
+		return Result{}, err
+	}
+
+	processors := []imageprocessor.ImageProcessor{
+		&imageprocessor.ColorExtractor{},
+	}
+
+	result := imageprocessor.ProcessDecodedImage(ctx, img, processors, quantizers, opts.numColors())
+	if result.Err != nil {
+		return Result{}, result.Err
+	}
+
+	if opts.KeepImage {
+		result.Image = img
+	}
+	if opts.Metrics {
+		result.Metrics = make(map[string]imageprocessor.QualityMetrics, len(result.Quantizers))
+		for _, name := range result.Quantizers {
+			colors, ok := result.Results[name]
+			if !ok {
+				continue
+			}
+			remapped, err := palette.Remap(img, colors)
+			if err != nil {
+				if result.Errors == nil {
+					result.Errors = make(map[string]error)
+				}
+				result.Errors[name] = fmt.Errorf("measuring quality: %w", err)
+				continue
+			}
+			result.Metrics[name] = imageprocessor.MeasureQuality(img, remapped)
+		}
+	}
+	return result, nil
+}
+
+// ExtractFile extracts the palette of the image at filePath. Failures are
+// reported in the returned Result's Err field.
+func ExtractFile(ctx context.Context, filePath string, opts Options) Result {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Result{FilePath: filePath, Err: err}
+	}
+	defer file.Close()
+
+	result, err := Extract(ctx, file, opts)
+	result.FilePath = filePath
+	result.Err = err
+	return result
+}
+
+// ExtractFiles extracts the palettes of several image files, in parallel unless
+// opts.Sequential is set. Results are returned in the order of filePaths.
+func ExtractFiles(ctx context.Context, filePaths []string, opts Options) []Result {
+	results := make([]Result, len(filePaths))
+
+	if opts.Sequential {
+		for i, filePath := range filePaths {
+			results[i] = ExtractFile(ctx, filePath, opts)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(filePaths))
+
+	for i, filePath := range filePaths {
+		go func(i int, filePath string) {
+			defer wg.Done()
+			results[i] = ExtractFile(ctx, filePath, opts)
+		}(i, filePath)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (o Options) quantizers() ([]imageprocessor.Quantizer, error) {
+	if len(o.Quantizers) > 0 {
+		return o.Quantizers, nil
+	}
+	quantizer, err := imageprocessor.NewQuantizer("kmeans")
+	if err != nil {
+		return nil, err
+	}
+	return []imageprocessor.Quantizer{quantizer}, nil
+}
+
+func (o Options) numColors() int {
+	if o.NumColors > 0 {
+		return o.NumColors
+	}
+	return imageprocessor.DefaultNumColors
+}
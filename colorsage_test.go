@@ -0,0 +1,91 @@
+package colorsage
+
+import (
+	"colorsage/imageprocessor"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// fixedQuantizer returns the same palette for every image
+type fixedQuantizer map[string]int
+
+func (q fixedQuantizer) Name() string { return "Fixed" }
+
+func (q fixedQuantizer) Quantize(map[string]int, int) (map[string]int, error) { return q, nil }
+
+func TestExtractImageMetrics(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+
+	result, err := ExtractImage(context.Background(), img, Options{Quantizers: []imageprocessor.Quantizer{fixedQuantizer{"#ffffff": 16}}, Metrics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := result.Metrics["Fixed"]; !ok || m.MeanDeltaE != 0 || m.SSIM != 1 {
+		t.Errorf("Metrics = %+v, want a perfect reproduction", result.Metrics)
+	}
+
+	// An indexed image can't hold this palette, so measuring it fails
+	tooMany := fixedQuantizer{}
+	for i := 0; i < 300; i++ {
+		tooMany[fmt.Sprintf("#%06x", i)] = 1
+	}
+	img.Set(0, 0, color.Black)
+	result, err = ExtractImage(context.Background(), img, Options{Quantizers: []imageprocessor.Quantizer{tooMany}, Metrics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Metrics["Fixed"]; ok {
+		t.Error("Metrics has an unmeasurable palette")
+	}
+	if err := result.Errors["Fixed"]; err == nil || !strings.Contains(err.Error(), "measuring quality") {
+		t.Errorf("Errors[Fixed] = %v, want the measuring error", err)
+	}
+	if len(result.Results["Fixed"]) != 300 {
+		t.Error("the palette that couldn't be measured is missing from Results")
+	}
+}
+
+func TestExtractImageOffset(t *testing.T) {
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	full := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if x < 4 {
+				full.Set(x, y, blue)
+			} else {
+				full.Set(x, y, red)
+			}
+		}
+	}
+	offset := image.NewRGBA(image.Rect(10, 20, 14, 28))
+	for y := 20; y < 28; y++ {
+		for x := 10; x < 14; x++ {
+			offset.Set(x, y, red)
+		}
+	}
+
+	for name, img := range map[string]image.Image{
+		"sub-image":     full.SubImage(image.Rect(4, 0, 8, 8)),
+		"offset origin": offset,
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := ExtractImage(context.Background(), img, Options{Quantizers: []imageprocessor.Quantizer{fixedQuantizer{"#ff0000": 32}}, Metrics: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.Results["ColorExtractor"]; len(got) != 1 || got["#ff0000"] != 32 {
+				t.Errorf("histogram = %v, want map[#ff0000:32]", got)
+			}
+			if m := result.Metrics["Fixed"]; m.MeanDeltaE != 0 || m.SSIM != 1 {
+				t.Errorf("Metrics = %+v, want a perfect reproduction", m)
+			}
+		})
+	}
+}
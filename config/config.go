@@ -8,6 +8,24 @@ var (
 	Fast                              bool
 	All                               bool
 	RawOutput                         bool
+	Format                            string
+	OutputFile                        string
+	NoOutputFile                      bool
+	OutputMode                        string
 	IncludeFullColorExtract           bool
+	Metrics                           bool
+	ColorNames                        string
+	NameMatch                         string
 	GeneratePaletteImagesInCurrentDir bool
+	PaletteFormats                    []string
+	PaletteDir                        string
+	PaletteNameTemplate               string
+	PaletteLab                        bool
+	PaletteLayout                     string
+	PaletteSort                       string
+	PaletteProportional               bool
+	PaletteLabels                     bool
+	PaletteBlockSize                  int
+	PaletteCard                       bool
+	PaletteDonut                      bool
 )
@@ -4,29 +4,34 @@ import (
 	"github.com/lucasb-eyer/go-colorful"
 )
 
+// AverageQuantizer averages equal-sized buckets of colors
 type AverageQuantizer struct{}
 
+// averageInfo describes AverageQuantizer to the quantizer registry
+var averageInfo = QuantizerInfo{
+	Name:        "average",
+	Aliases:     []string{"AverageQuantizer"},
+	Description: "averages equal-sized buckets of colors",
+	Rank:        3,
+	New: func(opts Options) (Quantizer, error) {
+		return AverageQuantizer{}, nil
+	},
+}
+
+func init() {
+	RegisterQuantizer(averageInfo)
+}
+
 func (q AverageQuantizer) Name() string {
 	return "AverageQuantizer"
 }
 
 func (q AverageQuantizer) Quantize(colorMap map[string]int, numColors int) (map[string]int, error) {
-	colors := q.extractColors(colorMap)
-	return q.simpleAverage(colors, numColors)
-}
-
-func (q AverageQuantizer) extractColors(colorMap map[string]int) []colorful.Color {
-	var colors []colorful.Color
-	for hex := range colorMap {
-		color, err := colorful.Hex(hex)
-		if err == nil {
-			colors = append(colors, color)
-		}
-	}
-	return colors
+	colors, counts := histogramColors(colorMap)
+	return q.simpleAverage(colors, counts, numColors)
 }
 
-func (q AverageQuantizer) simpleAverage(colors []colorful.Color, numColors int) (map[string]int, error) {
+func (q AverageQuantizer) simpleAverage(colors []colorful.Color, counts map[colorful.Color]int, numColors int) (map[string]int, error) {
 	if len(colors) == 0 {
 		return map[string]int{}, nil
 	}
@@ -46,7 +51,7 @@ func (q AverageQuantizer) simpleAverage(colors []colorful.Color, numColors int)
 		}
 		bucket := colors[i:end]
 		centroid := q.bucketCentroid(bucket)
-		quantizedPalette[centroid.Hex()] = len(bucket)
+		quantizedPalette[centroid.Hex()] += pixelCount(bucket, counts)
 	}
 
 	return quantizedPalette, nil
@@ -2,13 +2,15 @@ package imageprocessor
 
 import (
 	"image"
+	"image/color"
 	"runtime"
 	"sync"
 
 	"github.com/lucasb-eyer/go-colorful"
 )
 
-// ColorExtractor processor extracts the color frequencies
+// ColorExtractor processor extracts the color frequencies of the visible pixels. Translucent pixels
+// count with their straight color; fully transparent ones are left out.
 type ColorExtractor struct{}
 
 func (ce ColorExtractor) Name() string {
@@ -41,14 +43,19 @@ func (ce ColorExtractor) Process(img image.Image) (map[string]int, error) {
 				endY = height
 			}
 
-			// Iterate over the chunk's pixels
+			// Iterate over the chunk's pixels, offset by the image's origin, which isn't always 0,0
 			for y := startY; y < endY; y++ {
 				for x := 0; x < width; x++ {
-					r, g, b, _ := img.At(x, y).RGBA()
+					// Straight rather than alpha-premultiplied, so translucent pixels keep their
+					// color instead of darkening toward black; fully transparent pixels have none
+					n := color.NRGBA64Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+					if n.A == 0 {
+						continue
+					}
 					c := colorful.Color{
-						R: float64(r) / 65535.0,
-						G: float64(g) / 65535.0,
-						B: float64(b) / 65535.0,
+						R: float64(n.R) / 65535.0,
+						G: float64(n.G) / 65535.0,
+						B: float64(n.B) / 65535.0,
 					}
 					localColorMap[c]++
 				}
@@ -65,11 +72,11 @@ func (ce ColorExtractor) Process(img image.Image) (map[string]int, error) {
 
 	wg.Wait()
 
-	// Convert colorful.Color map to hex string map
+	// Convert colorful.Color map to hex string map, merging 16-bit colors that round to the same hex
 	hexMap := make(map[string]int)
 	for c, count := range colorMap {
 		hex := c.Hex()
-		hexMap[hex] = count
+		hexMap[hex] += count
 	}
 
 	return hexMap, nil
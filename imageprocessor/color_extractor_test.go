@@ -0,0 +1,77 @@
+package imageprocessor
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestColorExtractorAlpha(t *testing.T) {
+	tests := []struct {
+		name   string
+		pixels []color.NRGBA
+		want   map[string]int
+	}{
+		{
+			name:   "opaque",
+			pixels: []color.NRGBA{{255, 0, 0, 255}, {255, 0, 0, 255}, {0, 0, 255, 255}},
+			want:   map[string]int{"#ff0000": 2, "#0000ff": 1},
+		},
+		{
+			// Premultiplied, a half transparent red would be counted as #800000
+			name:   "translucent keeps its color",
+			pixels: []color.NRGBA{{255, 0, 0, 128}, {255, 0, 0, 255}, {0, 0, 255, 1}},
+			want:   map[string]int{"#ff0000": 2, "#0000ff": 1},
+		},
+		{
+			name:   "transparent pixels are left out",
+			pixels: []color.NRGBA{{255, 0, 0, 255}, {0, 0, 0, 0}, {255, 255, 255, 0}},
+			want:   map[string]int{"#ff0000": 1},
+		},
+		{
+			name:   "fully transparent",
+			pixels: []color.NRGBA{{0, 0, 0, 0}, {255, 0, 0, 0}},
+			want:   map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, len(tt.pixels), 1))
+			for x, c := range tt.pixels {
+				img.SetNRGBA(x, 0, c)
+			}
+			got, err := ColorExtractor{}.Process(img)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Process = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorExtractorMergesRoundedColors(t *testing.T) {
+	// 16-bit colors that round to the same 8-bit hex add up instead of replacing each other
+	img := image.NewNRGBA64(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA64(0, 0, color.NRGBA64{R: 0xfff0, A: 0xffff})
+	img.SetNRGBA64(1, 0, color.NRGBA64{R: 0xfff8, A: 0xffff})
+	img.SetNRGBA64(2, 0, color.NRGBA64{R: 0xffff, A: 0xffff})
+	got, err := ColorExtractor{}.Process(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"#ff0000": 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Process = %v, want %v", got, want)
+	}
+}
+
+func TestQuantizersEmptyHistogram(t *testing.T) {
+	for _, quantizer := range []Quantizer{KMeansQuantizer{Seed: 1}, MedianCutQuantizer{}, AverageQuantizer{}} {
+		palette, err := quantizer.Quantize(map[string]int{}, 5)
+		if err != nil || len(palette) != 0 {
+			t.Errorf("%s: Quantize of an empty histogram = %v, %v; want an empty palette", quantizer.Name(), palette, err)
+		}
+	}
+}
@@ -2,13 +2,14 @@
 package imageprocessor
 
 import (
-	"fmt"
+	"context"
 	"image"
 	_ "image/gif"  // Register GIF format
 	_ "image/jpeg" // Register JPEG format
 	_ "image/png"  // Register PNG format
 	"os"
 	"sync"
+	"time"
 
 	_ "golang.org/x/image/bmp"  // Register BMP format
 	_ "golang.org/x/image/tiff" // Register TIFF format
@@ -23,11 +24,19 @@ type ImageProcessor interface {
 
 // ImageResult holds the results of processing an image
 type ImageResult struct {
-	FilePath string
-	Results  map[string]map[string]int
-	Err      error
+	FilePath   string
+	Results    map[string]map[string]int
+	Quantizers []string                  // Quantizer names in the order they ran
+	Timings    map[string]time.Duration  // Time spent per stage, keyed by processor or quantizer name
+	Errors     map[string]error          // Quantizers that failed, keyed by name; their palettes are missing from Results unless only measuring them failed
+	Metrics    map[string]QualityMetrics // Quality of each quantizer's palette, when measured
+	Image      image.Image               // The decoded image, when kept for outputs that show it
+	Err        error
 }
 
+// DefaultNumColors is the palette size quantizers reduce to unless configured otherwise
+const DefaultNumColors = 5
+
 // ProcessImage processes a single image through a pipeline of processors and quantizers
 func ProcessImage(filePath string, processors []ImageProcessor, quantizers []Quantizer) ImageResult {
 	file, err := os.Open(filePath)
@@ -41,32 +50,66 @@ func ProcessImage(filePath string, processors []ImageProcessor, quantizers []Qua
 		return ImageResult{FilePath: filePath, Err: err}
 	}
 
+	result := ProcessDecodedImage(context.Background(), img, processors, quantizers, DefaultNumColors)
+	result.FilePath = filePath
+	return result
+}
+
+// ProcessDecodedImage runs an already decoded image through a pipeline of processors and quantizers.
+// A failing processor fails the whole image; a failing quantizer is recorded in Errors and the
+// remaining quantizers still run. Cancelling ctx stops the quantizers that support it and fails
+// the image.
+func ProcessDecodedImage(ctx context.Context, img image.Image, processors []ImageProcessor, quantizers []Quantizer, numColors int) ImageResult {
 	results := make(map[string]map[string]int)
+	timings := make(map[string]time.Duration)
 	var colorPalette map[string]int
+	var err error
 
 	// Step 1: Run ColorExtractor once
 	for _, processor := range processors {
 		if processor.Name() == "ColorExtractor" {
+			start := time.Now()
 			colorPalette, err = processor.Process(img)
 			if err != nil {
-				return ImageResult{FilePath: filePath, Err: err}
+				return ImageResult{Err: err}
 			}
+			timings[processor.Name()] = time.Since(start)
 			results[processor.Name()] = colorPalette
 			break // We only need to run ColorExtractor once
 		}
 	}
 
 	// Step 2: Pass the extracted color palette to each quantizer
-	numColors := 5 // Hardcoded number of colors, or make it configurable
+	var names []string
+	var errs map[string]error
 	for _, quantizer := range quantizers {
-		quantizedPalette, err := quantizer.Quantize(colorPalette, numColors)
+		if err := ctx.Err(); err != nil {
+			return ImageResult{Err: err}
+		}
+		name := quantizer.Name()
+		if _, seen := timings[name]; !seen {
+			names = append(names, name)
+		}
+
+		start := time.Now()
+		quantizedPalette, err := quantize(ctx, quantizer, colorPalette, numColors)
+		timings[name] = time.Since(start)
 		if err != nil {
-			return ImageResult{FilePath: filePath, Err: err}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ImageResult{Err: ctxErr}
+			}
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[name] = err
+			delete(results, name)
+			continue
 		}
-		results[quantizer.Name()] = quantizedPalette
+		delete(errs, name)
+		results[name] = quantizedPalette
 	}
 
-	return ImageResult{FilePath: filePath, Results: results}
+	return ImageResult{Results: results, Quantizers: names, Timings: timings, Errors: errs}
 }
 
 // ProcessPipeline takes a list of file paths and processes them through the pipeline
@@ -74,12 +117,10 @@ func ProcessPipeline(filePaths []string, processors []ImageProcessor, quantizers
 	results := make([]ImageResult, len(filePaths))
 
 	if sequential {
-		fmt.Println(Yellow + "Running in sequential mode..." + Reset)
 		for i, filePath := range filePaths {
 			results[i] = ProcessImage(filePath, processors, quantizers)
 		}
 	} else {
-		fmt.Println(Yellow + "Running in parallel mode..." + Reset)
 		var wg sync.WaitGroup
 		wg.Add(len(filePaths))
 
@@ -1,51 +1,82 @@
 package imageprocessor
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 
 	"github.com/lucasb-eyer/go-colorful"
 )
 
-type KMeansQuantizer struct{}
+// KMeansQuantizer clusters colors in Lab space using k-means
+type KMeansQuantizer struct {
+	Iterations int   // Number of refinement passes; 0 uses the default of 10
+	Seed       int64 // Seed for choosing the initial centroids; 0 picks them randomly
+}
+
+// kmeansInfo describes KMeansQuantizer to the quantizer registry
+var kmeansInfo = QuantizerInfo{
+	Name:        "kmeans",
+	Aliases:     []string{"k-means", "KMeansQuantizer"},
+	Description: "k-means clustering in Lab space (fastest, default)",
+	Rank:        1,
+	Options: []OptionSpec{
+		{Name: "iterations", Type: OptionInt, Default: 10, Description: "number of refinement passes"},
+		{Name: "seed", Type: OptionInt, Default: 0, Description: "seed for the initial centroids, 0 for random"},
+	},
+	New: func(opts Options) (Quantizer, error) {
+		if opts.Int("iterations") < 1 {
+			return nil, fmt.Errorf("kmeans: iterations must be at least 1")
+		}
+		return KMeansQuantizer{Iterations: opts.Int("iterations"), Seed: int64(opts.Int("seed"))}, nil
+	},
+}
+
+func init() {
+	RegisterQuantizer(kmeansInfo)
+}
 
 func (q KMeansQuantizer) Name() string {
 	return "KMeansQuantizer"
 }
 
 func (q KMeansQuantizer) Quantize(colorMap map[string]int, numColors int) (map[string]int, error) {
-	colors := q.extractColors(colorMap)
+	colors, counts := histogramColors(colorMap)
+	if len(colors) == 0 {
+		return map[string]int{}, nil
+	}
 	clusters := q.kmeans(colors, numColors)
 
 	quantizedPalette := make(map[string]int)
 	for _, cluster := range clusters {
+		if len(cluster) == 0 {
+			continue
+		}
 		centroid := q.clusterCentroid(cluster)
-		quantizedPalette[centroid.Hex()] = len(cluster)
+		quantizedPalette[centroid.Hex()] += pixelCount(cluster, counts)
 	}
 
 	return quantizedPalette, nil
 }
 
-func (q KMeansQuantizer) extractColors(colorMap map[string]int) []colorful.Color {
-	var colors []colorful.Color
-	for hex := range colorMap {
-		color, err := colorful.Hex(hex)
-		if err == nil {
-			colors = append(colors, color)
-		}
+func (q KMeansQuantizer) kmeans(colors []colorful.Color, numClusters int) [][]colorful.Color {
+	iterations := q.Iterations
+	if iterations <= 0 {
+		iterations = 10
+	}
+	pick := rand.Intn
+	if q.Seed != 0 {
+		pick = rand.New(rand.NewSource(q.Seed)).Intn
 	}
-	return colors
-}
 
-func (q KMeansQuantizer) kmeans(colors []colorful.Color, numClusters int) [][]colorful.Color {
 	centroids := make([]colorful.Color, numClusters)
 	for i := range centroids {
-		centroids[i] = colors[rand.Intn(len(colors))]
+		centroids[i] = colors[pick(len(colors))]
 	}
 
 	clusters := make([][]colorful.Color, numClusters)
 
-	for i := 0; i < 10; i++ {
+	for i := 0; i < iterations; i++ {
 		for j := range clusters {
 			clusters[j] = nil
 		}
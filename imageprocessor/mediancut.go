@@ -6,14 +6,30 @@ import (
 	"github.com/lucasb-eyer/go-colorful"
 )
 
+// MedianCutQuantizer recursively splits the color space along its widest RGB channel
 type MedianCutQuantizer struct{}
 
+// medianCutInfo describes MedianCutQuantizer to the quantizer registry
+var medianCutInfo = QuantizerInfo{
+	Name:        "mediancut",
+	Aliases:     []string{"median-cut", "MedianCutQuantizer"},
+	Description: "median cut along the widest RGB channel",
+	Rank:        2,
+	New: func(opts Options) (Quantizer, error) {
+		return MedianCutQuantizer{}, nil
+	},
+}
+
+func init() {
+	RegisterQuantizer(medianCutInfo)
+}
+
 func (q MedianCutQuantizer) Name() string {
 	return "MedianCutQuantizer"
 }
 
 func (q MedianCutQuantizer) Quantize(colorMap map[string]int, numColors int) (map[string]int, error) {
-	colors := q.extractColors(colorMap)
+	colors, counts := histogramColors(colorMap)
 	if len(colors) == 0 {
 		return map[string]int{}, nil
 	}
@@ -24,7 +40,7 @@ func (q MedianCutQuantizer) Quantize(colorMap map[string]int, numColors int) (ma
 	quantizedPalette := make(map[string]int)
 	for _, box := range boxes {
 		centroid := q.boxCentroid(box)
-		quantizedPalette[centroid.Hex()] = len(box.colors)
+		quantizedPalette[centroid.Hex()] += pixelCount(box.colors, counts)
 	}
 
 	return quantizedPalette, nil
@@ -34,17 +50,6 @@ type colorBox struct {
 	colors []colorful.Color
 }
 
-func (q MedianCutQuantizer) extractColors(colorMap map[string]int) []colorful.Color {
-	var colors []colorful.Color
-	for hex := range colorMap {
-		color, err := colorful.Hex(hex)
-		if err == nil {
-			colors = append(colors, color)
-		}
-	}
-	return colors
-}
-
 func (q MedianCutQuantizer) medianCut(boxes []colorBox, numColors int) []colorBox {
 	for len(boxes) < numColors {
 		newBoxes := []colorBox{}
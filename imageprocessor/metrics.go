@@ -0,0 +1,214 @@
+package imageprocessor
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// MaxPSNR is reported for images reproduced without any error, whose PSNR would be infinite
+const MaxPSNR = 100.0
+
+// deltaEBins and deltaEBinWidth size the histogram the CIEDE2000 percentile is read from. Differences
+// between sRGB colors stay well below the last bin, which also takes anything larger.
+const (
+	deltaEBins     = 12800
+	deltaEBinWidth = 0.01
+)
+
+// deltaECacheSize bounds the number of color pairs whose CIEDE2000 difference is remembered
+const deltaECacheSize = 1 << 16
+
+// ssimWindow and ssimStride set the size and spacing of the windows SSIM is averaged over
+const (
+	ssimWindow = 8
+	ssimStride = 4
+)
+
+// QualityMetrics measures how faithfully an image reduced to a palette reproduces the original
+type QualityMetrics struct {
+	MSE        float64 // Mean squared error over the 8-bit RGB channels
+	PSNR       float64 // Peak signal-to-noise ratio in dB, capped at MaxPSNR
+	SSIM       float64 // Mean structural similarity of the luma, 1 for identical images
+	MeanDeltaE float64 // Mean CIEDE2000 difference per pixel
+	P95DeltaE  float64 // 95th percentile of the CIEDE2000 difference per pixel
+}
+
+// MeasureQuality compares a reduced image to the original it was made from. Both must have the same size.
+// It reads the images a row at a time, so its memory use doesn't grow with their height.
+func MeasureQuality(original, reduced image.Image) QualityMetrics {
+	ob, rb := original.Bounds(), reduced.Bounds()
+	width, height := min(ob.Dx(), rb.Dx()), min(ob.Dy(), rb.Dy())
+	if width == 0 || height == 0 {
+		return QualityMetrics{PSNR: MaxPSNR, SSIM: 1}
+	}
+
+	var squaredError, deltaESum float64
+	var deltaEs deltaEHistogram
+	structure := newSSIMAccumulator(width, height)
+	// CIEDE2000 is costly and a reduced image has few colors, so it is computed once per pair
+	deltaECache := make(map[uint64]float64)
+	for y := 0; y < height; y++ {
+		originalLuma, reducedLuma := structure.row(y)
+		for x := 0; x < width; x++ {
+			oc := color.NRGBAModel.Convert(original.At(ob.Min.X+x, ob.Min.Y+y)).(color.NRGBA)
+			rc := color.NRGBAModel.Convert(reduced.At(rb.Min.X+x, rb.Min.Y+y)).(color.NRGBA)
+
+			dr, dg, db := float64(oc.R)-float64(rc.R), float64(oc.G)-float64(rc.G), float64(oc.B)-float64(rc.B)
+			squaredError += dr*dr + dg*dg + db*db
+
+			originalLuma[x] = luma(oc.R, oc.G, oc.B)
+			reducedLuma[x] = luma(rc.R, rc.G, rc.B)
+
+			key := uint64(oc.R)<<40 | uint64(oc.G)<<32 | uint64(oc.B)<<24 | uint64(rc.R)<<16 | uint64(rc.G)<<8 | uint64(rc.B)
+			deltaE, ok := deltaECache[key]
+			if !ok {
+				if len(deltaECache) >= deltaECacheSize {
+					// Photos have more distinct pairs than are worth keeping
+					clear(deltaECache)
+				}
+				deltaE = rgb8(oc).DistanceCIEDE2000(rgb8(rc)) * 100
+				deltaECache[key] = deltaE
+			}
+			deltaESum += deltaE
+			deltaEs.add(deltaE)
+		}
+		structure.addRow(y)
+	}
+
+	pixels := float64(width * height)
+	metrics := QualityMetrics{MSE: squaredError / (3 * pixels)}
+	metrics.PSNR = MaxPSNR
+	if metrics.MSE > 0 {
+		metrics.PSNR = math.Min(MaxPSNR, 10*math.Log10(255*255/metrics.MSE))
+	}
+	metrics.SSIM = structure.mean()
+	metrics.MeanDeltaE = deltaESum / pixels
+	metrics.P95DeltaE = deltaEs.percentile(0.95)
+	return metrics
+}
+
+// RankQuantizers orders the measured quantizers of a result from best to worst, by mean CIEDE2000
+// difference and then by SSIM
+func RankQuantizers(result ImageResult) []string {
+	var names []string
+	for _, name := range result.Quantizers {
+		if _, ok := result.Metrics[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := result.Metrics[names[i]], result.Metrics[names[j]]
+		if a.MeanDeltaE != b.MeanDeltaE {
+			return a.MeanDeltaE < b.MeanDeltaE
+		}
+		return a.SSIM > b.SSIM
+	})
+	return names
+}
+
+// rgb8 converts the color channels of an 8-bit color, ignoring its alpha
+func rgb8(c color.NRGBA) colorful.Color {
+	return colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}
+}
+
+// luma returns the Rec. 601 luma of an 8-bit RGB color
+func luma(r, g, b uint8) float64 {
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
+
+// ssimAccumulator averages the structural similarity of the luma over overlapping square windows,
+// keeping only the rows of the windows in progress
+type ssimAccumulator struct {
+	width, window, stride int
+	a, b                  []float64 // Luma of the last window rows, in a ring indexed by row modulo window
+	total                 float64
+	windows               int
+}
+
+func newSSIMAccumulator(width, height int) *ssimAccumulator {
+	window := min(ssimWindow, width, height)
+	return &ssimAccumulator{
+		width:  width,
+		window: window,
+		stride: max(1, min(ssimStride, window)),
+		a:      make([]float64, window*width),
+		b:      make([]float64, window*width),
+	}
+}
+
+// row returns the buffers to fill with the luma of row y of both images
+func (s *ssimAccumulator) row(y int) ([]float64, []float64) {
+	offset := (y % s.window) * s.width
+	return s.a[offset : offset+s.width], s.b[offset : offset+s.width]
+}
+
+// addRow adds the windows whose last row is y, once it has been filled
+func (s *ssimAccumulator) addRow(y int) {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+	y0 := y + 1 - s.window
+	if y0 < 0 || y0%s.stride != 0 {
+		return
+	}
+	for x0 := 0; x0+s.window <= s.width; x0 += s.stride {
+		var sumA, sumB, sumAA, sumBB, sumAB float64
+		for row := y0; row <= y; row++ {
+			offset := (row % s.window) * s.width
+			for x := x0; x < x0+s.window; x++ {
+				va, vb := s.a[offset+x], s.b[offset+x]
+				sumA += va
+				sumB += vb
+				sumAA += va * va
+				sumBB += vb * vb
+				sumAB += va * vb
+			}
+		}
+		n := float64(s.window * s.window)
+		meanA, meanB := sumA/n, sumB/n
+		varA, varB := sumAA/n-meanA*meanA, sumBB/n-meanB*meanB
+		covariance := sumAB/n - meanA*meanB
+		s.total += ((2*meanA*meanB + c1) * (2*covariance + c2)) /
+			((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+		s.windows++
+	}
+}
+
+// mean returns the average similarity over all windows
+func (s *ssimAccumulator) mean() float64 {
+	return s.total / float64(s.windows)
+}
+
+// deltaEHistogram counts CIEDE2000 differences in fixed bins of deltaEBinWidth, so percentiles take
+// the same memory for any image size. Each bin also keeps the largest difference that fell in it.
+type deltaEHistogram struct {
+	counts  [deltaEBins]int
+	largest [deltaEBins]float64
+	total   int
+}
+
+func (h *deltaEHistogram) add(deltaE float64) {
+	bin := min(int(deltaE/deltaEBinWidth), deltaEBins-1)
+	h.counts[bin]++
+	h.largest[bin] = math.Max(h.largest[bin], deltaE)
+	h.total++
+}
+
+// percentile returns the largest difference in the bin holding the p-th fraction of the counts,
+// which is at most deltaEBinWidth above the exact percentile
+func (h *deltaEHistogram) percentile(p float64) float64 {
+	rank := int(math.Ceil(p * float64(h.total)))
+	seen := 0
+	for bin, count := range h.counts {
+		seen += count
+		if count > 0 && seen >= rank {
+			return h.largest[bin]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package imageprocessor
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func uniformImage(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestMeasureQuality(t *testing.T) {
+	gray := uniformImage(16, 16, color.RGBA{128, 128, 128, 255})
+
+	identical := MeasureQuality(gray, gray)
+	if want := (QualityMetrics{PSNR: MaxPSNR, SSIM: 1}); identical != want {
+		t.Errorf("identical images: %+v, want %+v", identical, want)
+	}
+
+	// Off by 10 on every channel: MSE 100, PSNR 10·log10(255²/100)
+	shifted := MeasureQuality(gray, uniformImage(16, 16, color.RGBA{138, 138, 138, 255}))
+	if shifted.MSE != 100 {
+		t.Errorf("MSE = %g, want 100", shifted.MSE)
+	}
+	if want := 10 * math.Log10(255*255/100.0); math.Abs(shifted.PSNR-want) > 1e-9 {
+		t.Errorf("PSNR = %g, want %g", shifted.PSNR, want)
+	}
+	if shifted.MeanDeltaE <= 0 || math.Abs(shifted.MeanDeltaE-shifted.P95DeltaE) > 1e-9 {
+		t.Errorf("ΔE mean %g and p95 %g, want the same positive difference everywhere", shifted.MeanDeltaE, shifted.P95DeltaE)
+	}
+
+	// Black against white is as far apart as sRGB gets: ΔE2000 of 100
+	extreme := MeasureQuality(uniformImage(8, 8, color.Black), uniformImage(8, 8, color.White))
+	if math.Abs(extreme.MeanDeltaE-100) > 1e-4 {
+		t.Errorf("black against white ΔE = %g, want 100", extreme.MeanDeltaE)
+	}
+}
+
+func TestRankQuantizers(t *testing.T) {
+	result := ImageResult{
+		Quantizers: []string{"A", "B", "C", "D"},
+		Metrics: map[string]QualityMetrics{
+			"A": {MeanDeltaE: 3, SSIM: 0.9},
+			"B": {MeanDeltaE: 1, SSIM: 0.5},
+			"C": {MeanDeltaE: 3, SSIM: 0.95},
+		},
+	}
+	if got, want := RankQuantizers(result), []string{"B", "C", "A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RankQuantizers() = %v, want %v", got, want)
+	}
+}
+
+// referenceQuality computes SSIM and the ΔE percentile directly from whole luma planes and a
+// sorted list of differences, the way MeasureQuality would without streaming
+func referenceQuality(original, reduced *image.RGBA) (ssim, p95 float64) {
+	bounds := original.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	a, b := make([]float64, width*height), make([]float64, width*height)
+	var deltaEs []float64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			oc, rc := original.RGBAAt(x, y), reduced.RGBAAt(x, y)
+			a[y*width+x], b[y*width+x] = luma(oc.R, oc.G, oc.B), luma(rc.R, rc.G, rc.B)
+			on, rn := color.NRGBA(oc), color.NRGBA(rc)
+			deltaEs = append(deltaEs, rgb8(on).DistanceCIEDE2000(rgb8(rn))*100)
+		}
+	}
+	sort.Float64s(deltaEs)
+	p95 = deltaEs[int(math.Ceil(0.95*float64(len(deltaEs))))-1]
+
+	const c1, c2 = (0.01 * 255) * (0.01 * 255), (0.03 * 255) * (0.03 * 255)
+	window := min(ssimWindow, width, height)
+	stride := max(1, min(ssimStride, window))
+	var total float64
+	var windows int
+	for y0 := 0; y0+window <= height; y0 += stride {
+		for x0 := 0; x0+window <= width; x0 += stride {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for y := y0; y < y0+window; y++ {
+				for x := x0; x < x0+window; x++ {
+					va, vb := a[y*width+x], b[y*width+x]
+					sumA, sumB = sumA+va, sumB+vb
+					sumAA, sumBB, sumAB = sumAA+va*va, sumBB+vb*vb, sumAB+va*vb
+				}
+			}
+			n := float64(window * window)
+			meanA, meanB := sumA/n, sumB/n
+			varA, varB := sumAA/n-meanA*meanA, sumBB/n-meanB*meanB
+			covariance := sumAB/n - meanA*meanB
+			total += ((2*meanA*meanB + c1) * (2*covariance + c2)) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+	return total / float64(windows), p95
+}
+
+func TestMeasureQualityStreaming(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// Sizes that don't line up with the SSIM window and stride, and images smaller than a window
+	for _, size := range []image.Point{{37, 29}, {8, 8}, {5, 11}, {64, 3}} {
+		original := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+		reduced := image.NewRGBA(original.Bounds())
+		for i := 0; i < len(original.Pix); i += 4 {
+			v := uint8(rng.Intn(256))
+			copy(original.Pix[i:], []uint8{v, uint8(rng.Intn(256)), 255 - v, 255})
+			// The reduced image keeps the original's structure with a few levels per channel
+			for c := 0; c < 3; c++ {
+				reduced.Pix[i+c] = original.Pix[i+c] / 64 * 64
+			}
+			reduced.Pix[i+3] = 255
+		}
+
+		metrics := MeasureQuality(original, reduced)
+		ssim, p95 := referenceQuality(original, reduced)
+		if math.Abs(metrics.SSIM-ssim) > 1e-9 {
+			t.Errorf("%v: SSIM = %g, want %g", size, metrics.SSIM, ssim)
+		}
+		if metrics.P95DeltaE < p95 || metrics.P95DeltaE > p95+deltaEBinWidth {
+			t.Errorf("%v: p95 ΔE = %g, want within %g above %g", size, metrics.P95DeltaE, deltaEBinWidth, p95)
+		}
+	}
+}
+
+func TestDeltaEHistogram(t *testing.T) {
+	var h deltaEHistogram
+	for i := 1; i <= 100; i++ {
+		h.add(float64(i) / 2)
+	}
+	h.add(1000) // Beyond the last bin
+	if got := h.percentile(0.95); got != 48 {
+		t.Errorf("p95 = %g, want 48", got)
+	}
+	if got := h.percentile(1); got != 1000 {
+		t.Errorf("p100 = %g, want the largest difference 1000", got)
+	}
+	if got := (&deltaEHistogram{}).percentile(0.95); got != 0 {
+		t.Errorf("empty p95 = %g, want 0", got)
+	}
+}
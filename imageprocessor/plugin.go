@@ -0,0 +1,203 @@
+package imageprocessor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// PluginPrefix is the executable name prefix used to discover external quantizers on $PATH.
+// An executable named colorsage-quantizer-foo is registered as the quantizer "foo".
+const PluginPrefix = "colorsage-quantizer-"
+
+// PluginProtocolVersion is the version of the JSON protocol spoken with external quantizers
+const PluginProtocolVersion = 1
+
+// DefaultPluginTimeout bounds how long an external quantizer may run
+const DefaultPluginTimeout = 30 * time.Second
+
+// pluginRank lists external quantizers after the built-in ones
+const pluginRank = 100
+
+// PluginRequest is written as JSON to the plugin's stdin
+type PluginRequest struct {
+	Version   int               `json:"version"`
+	NumColors int               `json:"num_colors"`
+	Options   map[string]string `json:"options,omitempty"`
+	Histogram []PluginColor     `json:"histogram"`
+}
+
+// PluginResponse is read as JSON from the plugin's stdout
+type PluginResponse struct {
+	Palette []PluginColor `json:"palette"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// PluginColor is a single color and its occurrence count
+type PluginColor struct {
+	Hex   string `json:"hex"`
+	Count int    `json:"count"`
+}
+
+// PluginQuantizer runs an external executable that speaks the JSON plugin protocol
+type PluginQuantizer struct {
+	PluginName string
+	Path       string
+	Timeout    time.Duration
+	Options    map[string]string
+}
+
+func (q PluginQuantizer) Name() string {
+	return q.PluginName
+}
+
+func (q PluginQuantizer) Quantize(colorMap map[string]int, numColors int) (map[string]int, error) {
+	return q.QuantizeContext(context.Background(), colorMap, numColors)
+}
+
+// QuantizeContext runs the plugin, killing it when ctx is done or its timeout runs out
+func (q PluginQuantizer) QuantizeContext(parent context.Context, colorMap map[string]int, numColors int) (map[string]int, error) {
+	request := PluginRequest{
+		Version:   PluginProtocolVersion,
+		NumColors: numColors,
+		Options:   q.Options,
+		Histogram: make([]PluginColor, 0, len(colorMap)),
+	}
+	for hex, count := range colorMap {
+		request.Histogram = append(request.Histogram, PluginColor{Hex: hex, Count: count})
+	}
+	// Send the histogram in a stable order so plugins behave deterministically
+	sort.Slice(request.Histogram, func(i, j int) bool {
+		if request.Histogram[i].Count != request.Histogram[j].Count {
+			return request.Histogram[i].Count > request.Histogram[j].Count
+		}
+		return request.Histogram[i].Hex < request.Histogram[j].Hex
+	})
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: encoding request: %w", q.PluginName, err)
+	}
+
+	timeout := q.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, q.Path)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	command.WaitDelay = time.Second // Don't hang on grandchildren still holding stdout after a kill
+
+	if err := command.Run(); err != nil {
+		if err := parent.Err(); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", q.PluginName, err)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("plugin %s: timed out after %s", q.PluginName, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s: %w: %s", q.PluginName, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s: %w", q.PluginName, err)
+	}
+
+	var response PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid response: %w", q.PluginName, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s: %s", q.PluginName, response.Error)
+	}
+
+	palette := make(map[string]int, len(response.Palette))
+	for _, c := range response.Palette {
+		color, err := colorful.Hex(c.Hex)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: invalid color %q in palette", q.PluginName, c.Hex)
+		}
+		palette[color.Hex()] += c.Count
+	}
+	return palette, nil
+}
+
+// pluginInfo describes an external quantizer to the registry
+func pluginInfo(name, path string) QuantizerInfo {
+	return QuantizerInfo{
+		Name:        name,
+		Description: fmt.Sprintf("external plugin (%s)", path),
+		Rank:        pluginRank,
+		Options: []OptionSpec{
+			{Name: "timeout", Type: OptionString, Default: DefaultPluginTimeout.String(), Description: "maximum run time of the plugin"},
+		},
+		AllowUnknownOptions: true,
+		New: func(opts Options) (Quantizer, error) {
+			timeout, err := time.ParseDuration(opts.String("timeout"))
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: invalid timeout: %w", name, err)
+			}
+			extra := make(map[string]string)
+			for key, value := range opts {
+				if key != "timeout" {
+					extra[key] = fmt.Sprint(value)
+				}
+			}
+			return PluginQuantizer{PluginName: name, Path: path, Timeout: timeout, Options: extra}, nil
+		},
+	}
+}
+
+// DiscoverPlugins scans the directories in $PATH for external quantizers and registers them.
+// Plugins whose name clashes with an already registered quantizer are skipped; the first
+// match on $PATH wins. It returns the names of the newly registered plugins.
+func DiscoverPlugins() []string {
+	var registered []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), PluginPrefix)
+			if !ok || name == "" || entry.IsDir() {
+				continue
+			}
+			name = strings.TrimSuffix(name, ".exe")
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			if _, exists := LookupQuantizer(name); exists {
+				continue
+			}
+			RegisterQuantizer(pluginInfo(name, path))
+			registered = append(registered, name)
+		}
+	}
+	return registered
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return info.Mode()&0111 != 0 || strings.EqualFold(filepath.Ext(path), ".exe")
+}
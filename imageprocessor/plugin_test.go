@@ -0,0 +1,207 @@
+package imageprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pluginModeEnv tells the test binary, re-executed as a plugin, how to behave
+const pluginModeEnv = "COLORSAGE_TEST_PLUGIN_MODE"
+
+// TestMain lets the test binary act as an external quantizer when it runs under a plugin name
+func TestMain(m *testing.M) {
+	if strings.HasPrefix(filepath.Base(os.Args[0]), PluginPrefix) {
+		os.Exit(runTestPlugin(os.Getenv(pluginModeEnv)))
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin answers a plugin request according to mode and returns the exit code
+func runTestPlugin(mode string) int {
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, "decoding request:", err)
+		return 1
+	}
+
+	switch mode {
+	case "error":
+		fmt.Println(`{"error": "no colors to spare"}`)
+	case "invalid":
+		fmt.Println("this is not JSON")
+	case "bad-hex":
+		fmt.Println(`{"palette": [{"hex": "#zzzzzz", "count": 1}]}`)
+	case "exit":
+		fmt.Fprintln(os.Stderr, "plugin crashed")
+		return 3
+	case "hang":
+		time.Sleep(10 * time.Second)
+	case "orphan":
+		// Leave a grandchild holding stdout so that only WaitDelay ends the wait
+		self, _ := os.Executable()
+		child := exec.Command(self)
+		child.Env = append(os.Environ(), pluginModeEnv+"=hang")
+		child.Stdin = strings.NewReader(`{}`)
+		child.Stdout = os.Stdout
+		if err := child.Start(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		time.Sleep(10 * time.Second)
+	default:
+		// Echo the request so tests can check what the plugin received
+		response := PluginResponse{Palette: request.Histogram}
+		if len(response.Palette) > request.NumColors {
+			response.Palette = response.Palette[:request.NumColors]
+		}
+		for key, value := range request.Options {
+			response.Palette = append(response.Palette, PluginColor{Hex: value, Count: len(key)})
+		}
+		json.NewEncoder(os.Stdout).Encode(response)
+	}
+	return 0
+}
+
+// testPlugin links the test binary into a temporary directory under a plugin name
+func testPlugin(t *testing.T, mode string) PluginQuantizer {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), PluginPrefix+"x")
+	if err := os.Symlink(self, path); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(pluginModeEnv, mode)
+	return PluginQuantizer{PluginName: "x", Path: path, Timeout: 5 * time.Second}
+}
+
+func TestPluginQuantizer(t *testing.T) {
+	histogram := map[string]int{"#ff0000": 5, "#00ff00": 3, "#0000ff": 1}
+
+	tests := []struct {
+		mode    string
+		want    map[string]int
+		wantErr string
+	}{
+		{mode: "good", want: map[string]int{"#ff0000": 5, "#00ff00": 3}},
+		{mode: "error", wantErr: "plugin x: no colors to spare"},
+		{mode: "invalid", wantErr: "plugin x: invalid response"},
+		{mode: "bad-hex", wantErr: `plugin x: invalid color "#zzzzzz" in palette`},
+		{mode: "exit", wantErr: "plugin x: exit status 3: plugin crashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			got, err := testPlugin(t, tt.mode).Quantize(histogram, 2)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Quantize() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Quantize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Quantize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPluginQuantizerTimeout(t *testing.T) {
+	for _, mode := range []string{"hang", "orphan"} {
+		t.Run(mode, func(t *testing.T) {
+			plugin := testPlugin(t, mode)
+			plugin.Timeout = 200 * time.Millisecond
+
+			start := time.Now()
+			_, err := plugin.Quantize(map[string]int{"#ffffff": 1}, 1)
+			if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+				t.Fatalf("Quantize() error = %v, want a timeout", err)
+			}
+			// The timeout plus WaitDelay, with room for a slow machine
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Quantize() took %s after the timeout", elapsed)
+			}
+		})
+	}
+}
+
+func TestPluginQuantizerCancel(t *testing.T) {
+	plugin := testPlugin(t, "hang")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := plugin.QuantizeContext(ctx, map[string]int{"#ffffff": 1}, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("QuantizeContext() error = %v, want it cancelled", err)
+	}
+	// Well before the default timeout
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("QuantizeContext() took %s after the cancellation", elapsed)
+	}
+
+	// Cancelling the pipeline fails the image rather than recording a quantizer error
+	result := ProcessDecodedImage(ctx, uniformImage(2, 2, color.White), []ImageProcessor{ColorExtractor{}}, []Quantizer{plugin}, 1)
+	if !errors.Is(result.Err, context.Canceled) {
+		t.Errorf("ProcessDecodedImage() error = %v, want it cancelled", result.Err)
+	}
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Symlink(self, filepath.Join(dir, PluginPrefix+"discovered")); err != nil {
+		t.Fatal(err)
+	}
+	// Neither a non-executable file nor a built-in name is registered
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"ignored"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(self, filepath.Join(dir, PluginPrefix+"kmeans")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv(pluginModeEnv, "good")
+
+	if got := DiscoverPlugins(); !reflect.DeepEqual(got, []string{"discovered"}) {
+		t.Fatalf("DiscoverPlugins() = %v, want [discovered]", got)
+	}
+	if got := DiscoverPlugins(); len(got) != 0 {
+		t.Errorf("DiscoverPlugins() again = %v, want nothing new", got)
+	}
+
+	// Unknown options are passed through to the plugin, which echoes them back here
+	quantizers, err := ParseQuantizerSpec("discovered:timeout=5s:tint=#123456")
+	if err != nil {
+		t.Fatalf("ParseQuantizerSpec() error = %v", err)
+	}
+	got, err := quantizers[0].Quantize(map[string]int{"#ff0000": 2}, 1)
+	if err != nil {
+		t.Fatalf("Quantize() error = %v", err)
+	}
+	if want := map[string]int{"#ff0000": 2, "#123456": 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Quantize() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseQuantizerSpec("discovered:timeout=soon"); err == nil {
+		t.Error("ParseQuantizerSpec() with an invalid timeout succeeded")
+	}
+}
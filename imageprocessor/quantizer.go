@@ -1,7 +1,23 @@
 package imageprocessor
 
+import "context"
+
 // Quantizer interface for quantizing color palettes
 type Quantizer interface {
 	Name() string
 	Quantize(colors map[string]int, numColors int) (map[string]int, error)
 }
+
+// ContextQuantizer is a Quantizer that can be stopped while it runs, such as an external plugin
+type ContextQuantizer interface {
+	Quantizer
+	QuantizeContext(ctx context.Context, colors map[string]int, numColors int) (map[string]int, error)
+}
+
+// quantize runs the quantizer, stopping it with ctx if it supports that
+func quantize(ctx context.Context, quantizer Quantizer, colors map[string]int, numColors int) (map[string]int, error) {
+	if q, ok := quantizer.(ContextQuantizer); ok {
+		return q.QuantizeContext(ctx, colors, numColors)
+	}
+	return quantizer.Quantize(colors, numColors)
+}
@@ -0,0 +1,43 @@
+package imageprocessor
+
+import (
+	"testing"
+)
+
+func TestQuantizerCountsPixels(t *testing.T) {
+	// Each palette entry counts the pixels it stands for, not the distinct colors it merged, so
+	// the counts of a palette add up to the pixels of the histogram
+	histogram := map[string]int{
+		"#101010": 9000,
+		"#f0f0f0": 10,
+		"#f4f4f4": 20,
+		"#f8f8f8": 30,
+		"#fcfcfc": 40,
+	}
+	quantizers := []Quantizer{
+		KMeansQuantizer{Seed: 1},
+		MedianCutQuantizer{},
+		AverageQuantizer{},
+	}
+	for _, quantizer := range quantizers {
+		t.Run(quantizer.Name(), func(t *testing.T) {
+			palette, err := quantizer.Quantize(histogram, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := TotalCount(palette), TotalCount(histogram); got != want {
+				t.Errorf("palette %v counts %d pixels, want %d", palette, got, want)
+			}
+		})
+	}
+}
+
+func TestHistogramColors(t *testing.T) {
+	colors, counts := histogramColors(map[string]int{"#FF0000": 2, "#ff0000": 3, "#00ff00": 1, "nope": 4})
+	if len(colors) != 2 {
+		t.Fatalf("got %d colors, want 2", len(colors))
+	}
+	if got := pixelCount(colors, counts); got != 6 {
+		t.Errorf("pixelCount = %d, want 6", got)
+	}
+}
@@ -0,0 +1,310 @@
+package imageprocessor
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OptionType identifies the value type of a quantizer option
+type OptionType int
+
+const (
+	OptionInt OptionType = iota
+	OptionFloat
+	OptionBool
+	OptionString
+)
+
+func (t OptionType) String() string {
+	switch t {
+	case OptionInt:
+		return "int"
+	case OptionFloat:
+		return "float"
+	case OptionBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// OptionSpec describes a single option accepted by a quantizer
+type OptionSpec struct {
+	Name        string
+	Type        OptionType
+	Default     interface{}
+	Description string
+}
+
+// Options holds typed option values for a quantizer, keyed by option name
+type Options map[string]interface{}
+
+// Int returns the named option as an int, or 0 if it is not set
+func (o Options) Int(name string) int {
+	v, _ := o[name].(int)
+	return v
+}
+
+// Float returns the named option as a float64, or 0 if it is not set
+func (o Options) Float(name string) float64 {
+	v, _ := o[name].(float64)
+	return v
+}
+
+// Bool returns the named option as a bool, or false if it is not set
+func (o Options) Bool(name string) bool {
+	v, _ := o[name].(bool)
+	return v
+}
+
+// String returns the named option as a string, or "" if it is not set
+func (o Options) String(name string) string {
+	v, _ := o[name].(string)
+	return v
+}
+
+// QuantizerInfo describes a registered quantizer and how to construct it
+type QuantizerInfo struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Options     []OptionSpec
+	New         func(opts Options) (Quantizer, error)
+
+	// Rank orders the quantizers in listings and in the order --all runs them, lowest first.
+	// The built-in quantizers rank by speed from 1; quantizers of equal rank keep registration order.
+	Rank int
+
+	// AllowUnknownOptions keeps options missing from the schema as raw strings instead of rejecting them
+	AllowUnknownOptions bool
+}
+
+// Defaults returns the default values of every option in the schema
+func (info QuantizerInfo) Defaults() Options {
+	opts := make(Options, len(info.Options))
+	for _, spec := range info.Options {
+		if spec.Default != nil {
+			opts[spec.Name] = spec.Default
+		}
+	}
+	return opts
+}
+
+// ParseOptions converts raw key=value strings into typed options according to the schema
+func (info QuantizerInfo) ParseOptions(raw map[string]string) (Options, error) {
+	opts := info.Defaults()
+	for key, value := range raw {
+		spec, ok := info.option(key)
+		if !ok && info.AllowUnknownOptions {
+			opts[key] = value
+			continue
+		}
+		if !ok {
+			return nil, fmt.Errorf("quantizer %s has no option %q", info.Name, key)
+		}
+		parsed, err := parseOptionValue(spec.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for option %s of quantizer %s: expected %s", value, key, info.Name, spec.Type)
+		}
+		opts[key] = parsed
+	}
+	return opts, nil
+}
+
+func (info QuantizerInfo) option(name string) (OptionSpec, bool) {
+	for _, spec := range info.Options {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return OptionSpec{}, false
+}
+
+func parseOptionValue(t OptionType, value string) (interface{}, error) {
+	switch t {
+	case OptionInt:
+		return strconv.Atoi(value)
+	case OptionFloat:
+		return strconv.ParseFloat(value, 64)
+	case OptionBool:
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []QuantizerInfo
+)
+
+// RegisterQuantizer adds a quantizer to the registry. It panics if the name or an alias is already taken.
+func RegisterQuantizer(info QuantizerInfo) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, name := range append([]string{info.Name}, info.Aliases...) {
+		if _, ok := lookupLocked(name); ok {
+			panic(fmt.Sprintf("imageprocessor: quantizer %q registered twice", name))
+		}
+	}
+	// Keep the registry sorted by rank, after the quantizers of the same rank
+	i := sort.Search(len(registry), func(i int) bool { return registry[i].Rank > info.Rank })
+	registry = slices.Insert(registry, i, info)
+}
+
+// LookupQuantizer finds a registered quantizer by name or alias, ignoring case
+func LookupQuantizer(name string) (QuantizerInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return lookupLocked(name)
+}
+
+func lookupLocked(name string) (QuantizerInfo, bool) {
+	for _, info := range registry {
+		if strings.EqualFold(info.Name, name) {
+			return info, true
+		}
+		for _, alias := range info.Aliases {
+			if strings.EqualFold(alias, name) {
+				return info, true
+			}
+		}
+	}
+	return QuantizerInfo{}, false
+}
+
+// RegisteredQuantizers returns all registered quantizers by rank
+func RegisteredQuantizers() []QuantizerInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]QuantizerInfo(nil), registry...)
+}
+
+// QuantizerNames returns the names of all registered quantizers by rank
+func QuantizerNames() []string {
+	var names []string
+	for _, info := range RegisteredQuantizers() {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+// NewQuantizer constructs a registered quantizer with its default options
+func NewQuantizer(name string) (Quantizer, error) {
+	info, ok := LookupQuantizer(name)
+	if !ok {
+		return nil, fmt.Errorf("invalid quantizer type: %s. Supported types: %s", name, strings.Join(QuantizerNames(), ", "))
+	}
+	return info.New(info.Defaults())
+}
+
+// AllQuantizers constructs every registered quantizer with its default options
+func AllQuantizers() ([]Quantizer, error) {
+	var quantizers []Quantizer
+	for _, info := range RegisteredQuantizers() {
+		quantizer, err := info.New(info.Defaults())
+		if err != nil {
+			return nil, err
+		}
+		quantizers = append(quantizers, quantizer)
+	}
+	return quantizers, nil
+}
+
+// ParseQuantizerSpec parses a comma-separated list of quantizers with optional options,
+// e.g. "kmeans:iterations=30,mediancut". Additional options for the same quantizer may
+// follow as further comma-separated key=value pairs, e.g. "kmeans:iterations=30,seed=1".
+// Each quantizer may be given once.
+func ParseQuantizerSpec(spec string) ([]Quantizer, error) {
+	type entry struct {
+		info QuantizerInfo
+		raw  map[string]string
+	}
+	var entries []*entry
+
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		name, optionText, hasOptions := strings.Cut(token, ":")
+		if !hasOptions && strings.Contains(token, "=") {
+			// A bare key=value continues the options of the previous quantizer
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("option %q given before any quantizer", token)
+			}
+			optionText, name = token, ""
+		}
+
+		var current *entry
+		if name != "" {
+			info, ok := LookupQuantizer(name)
+			if !ok {
+				return nil, fmt.Errorf("invalid quantizer type: %s. Supported types: %s", name, strings.Join(QuantizerNames(), ", "))
+			}
+			// A repeated quantizer would report its palette under the same name as the first
+			for _, e := range entries {
+				if e.info.Name == info.Name {
+					return nil, fmt.Errorf("quantizer %s is given more than once", info.Name)
+				}
+			}
+			current = &entry{info: info, raw: map[string]string{}}
+			entries = append(entries, current)
+		} else {
+			current = entries[len(entries)-1]
+		}
+
+		if optionText == "" {
+			continue
+		}
+		for _, pair := range strings.Split(optionText, ":") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid option %q for quantizer %s: expected key=value", pair, current.info.Name)
+			}
+			current.raw[key] = value
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no quantizer specified")
+	}
+
+	quantizers := make([]Quantizer, 0, len(entries))
+	for _, e := range entries {
+		opts, err := e.info.ParseOptions(e.raw)
+		if err != nil {
+			return nil, err
+		}
+		quantizer, err := e.info.New(opts)
+		if err != nil {
+			return nil, err
+		}
+		quantizers = append(quantizers, quantizer)
+	}
+	return quantizers, nil
+}
+
+// QuantizerHelp returns a human-readable listing of the registered quantizers and their options
+func QuantizerHelp() string {
+	var sb strings.Builder
+	for _, info := range RegisteredQuantizers() {
+		sb.WriteString(fmt.Sprintf("  %s", info.Name))
+		if len(info.Aliases) > 0 {
+			aliases := append([]string(nil), info.Aliases...)
+			sort.Strings(aliases)
+			sb.WriteString(fmt.Sprintf(" (aliases: %s)", strings.Join(aliases, ", ")))
+		}
+		sb.WriteString(fmt.Sprintf(": %s\n", info.Description))
+		for _, spec := range info.Options {
+			sb.WriteString(fmt.Sprintf("      %s=<%s>  %s (default %v)\n", spec.Name, spec.Type, spec.Description, spec.Default))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package imageprocessor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuantizerSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []Quantizer
+	}{
+		{"kmeans", []Quantizer{KMeansQuantizer{Iterations: 10}}},
+		{"K-Means", []Quantizer{KMeansQuantizer{Iterations: 10}}},
+		{"KMeansQuantizer, median-cut ,average", []Quantizer{KMeansQuantizer{Iterations: 10}, MedianCutQuantizer{}, AverageQuantizer{}}},
+		{"kmeans:iterations=30", []Quantizer{KMeansQuantizer{Iterations: 30}}},
+		{"kmeans:iterations=30:seed=2", []Quantizer{KMeansQuantizer{Iterations: 30, Seed: 2}}},
+		// Bare key=value pairs continue the options of the quantizer before them
+		{"kmeans:iterations=30,seed=2,mediancut", []Quantizer{KMeansQuantizer{Iterations: 30, Seed: 2}, MedianCutQuantizer{}}},
+		{"mediancut,kmeans,seed=5", []Quantizer{MedianCutQuantizer{}, KMeansQuantizer{Iterations: 10, Seed: 5}}},
+		{"kmeans,,", []Quantizer{KMeansQuantizer{Iterations: 10}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuantizerSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParseQuantizerSpec(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuantizerSpec(%q) = %#v, want %#v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuantizerSpecErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string // Part of the error message
+	}{
+		{"", "no quantizer specified"},
+		{" , ", "no quantizer specified"},
+		{"octree", "invalid quantizer type: octree"},
+		{"seed=1,kmeans", "given before any quantizer"},
+		{"kmeans:iterations", "expected key=value"},
+		{"kmeans:=3", "expected key=value"},
+		{"kmeans:depth=3", "has no option \"depth\""},
+		{"mediancut:seed=1", "has no option \"seed\""},
+		{"kmeans:iterations=many", "expected int"},
+		{"kmeans:iterations=1.5", "expected int"},
+		{"kmeans:iterations=0", "iterations must be at least 1"},
+		{"kmeans,mediancut,kmeans", "kmeans is given more than once"},
+		{"kmeans:seed=1,k-means:seed=2", "kmeans is given more than once"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuantizerSpec(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseQuantizerSpec(%q) error = %v, want one containing %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestParseOptionsTypes(t *testing.T) {
+	info := QuantizerInfo{
+		Name: "typed",
+		Options: []OptionSpec{
+			{Name: "n", Type: OptionInt, Default: 1},
+			{Name: "x", Type: OptionFloat, Default: 0.5},
+			{Name: "on", Type: OptionBool},
+			{Name: "s", Type: OptionString, Default: "a"},
+		},
+	}
+	opts, err := info.ParseOptions(map[string]string{"x": "2.5", "on": "true", "s": "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Int("n") != 1 || opts.Float("x") != 2.5 || !opts.Bool("on") || opts.String("s") != "b" {
+		t.Errorf("ParseOptions = %v", opts)
+	}
+
+	for raw, want := range map[string]string{"x": "expected float", "on": "expected bool"} {
+		if _, err := info.ParseOptions(map[string]string{raw: "nope"}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseOptions(%s=nope) error = %v, want one containing %q", raw, err, want)
+		}
+	}
+
+	info.AllowUnknownOptions = true
+	opts, err = info.ParseOptions(map[string]string{"extra": "7"})
+	if err != nil || opts.String("extra") != "7" {
+		t.Errorf("ParseOptions with unknown options allowed = %v, %v", opts, err)
+	}
+}
+
+func TestRegisteredQuantizersRank(t *testing.T) {
+	names := QuantizerNames()
+	if len(names) < 3 || !reflect.DeepEqual(names[:3], []string{"kmeans", "mediancut", "average"}) {
+		t.Errorf("QuantizerNames = %v, want the built-in quantizers fastest first", names)
+	}
+	if plugin := pluginInfo("zz", "/bin/zz"); plugin.Rank <= averageInfo.Rank {
+		t.Errorf("plugins rank %d, want after the built-in quantizers", plugin.Rank)
+	}
+}
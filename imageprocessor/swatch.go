@@ -0,0 +1,65 @@
+package imageprocessor
+
+import (
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Swatch is a single palette color and its occurrence count
+type Swatch struct {
+	Hex   string
+	Count int
+}
+
+// SortedSwatches returns the colors of a palette ordered by descending count, ties broken by hex
+func SortedSwatches(palette map[string]int) []Swatch {
+	swatches := make([]Swatch, 0, len(palette))
+	for hex, count := range palette {
+		swatches = append(swatches, Swatch{Hex: hex, Count: count})
+	}
+	sort.Slice(swatches, func(i, j int) bool {
+		if swatches[i].Count != swatches[j].Count {
+			return swatches[i].Count > swatches[j].Count
+		}
+		return swatches[i].Hex < swatches[j].Hex
+	})
+	return swatches
+}
+
+// TotalCount returns the sum of all counts in a palette
+func TotalCount(palette map[string]int) int {
+	total := 0
+	for _, count := range palette {
+		total += count
+	}
+	return total
+}
+
+// histogramColors parses the colors of a histogram with their counts, merging hex codes of the same
+// color and skipping invalid ones
+func histogramColors(colorMap map[string]int) ([]colorful.Color, map[colorful.Color]int) {
+	var colors []colorful.Color
+	counts := make(map[colorful.Color]int, len(colorMap))
+	for hex, count := range colorMap {
+		c, err := colorful.Hex(hex)
+		if err != nil {
+			continue
+		}
+		if _, seen := counts[c]; !seen {
+			colors = append(colors, c)
+		}
+		counts[c] += count
+	}
+	return colors, counts
+}
+
+// pixelCount returns the number of pixels of a group of histogram colors, so quantized palettes
+// report coverage rather than how many distinct colors each entry merged
+func pixelCount(colors []colorful.Color, counts map[colorful.Color]int) int {
+	total := 0
+	for _, c := range colors {
+		total += counts[c]
+	}
+	return total
+}
@@ -0,0 +1,71 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// BrandColor is a color of a reference brand palette
+type BrandColor struct {
+	Name string // Optional
+	Hex  string
+}
+
+// OffBrandSwatch is a palette color too far from every brand color
+type OffBrandSwatch struct {
+	Hex      string
+	Coverage float64    // Share of the palette's counts, from 0 to 1
+	Nearest  BrandColor // The closest brand color
+	DeltaE   float64    // CIEDE2000 difference to the closest brand color
+}
+
+// BrandCompliance is the result of checking a palette against a brand palette
+type BrandCompliance struct {
+	OffBrand         []OffBrandSwatch // Most covering first
+	OffBrandCoverage float64          // Share of the palette's counts that is off-brand, from 0 to 1
+}
+
+// CheckBrandCompliance finds the palette colors whose nearest brand color is more than tolerance
+// CIEDE2000 units away
+func CheckBrandCompliance(colors map[string]int, brand []BrandColor, tolerance float64) (BrandCompliance, error) {
+	if len(brand) == 0 {
+		return BrandCompliance{}, fmt.Errorf("brand palette is empty")
+	}
+	brandColors := make([]colorful.Color, len(brand))
+	for i, entry := range brand {
+		c, err := colorful.Hex(entry.Hex)
+		if err != nil {
+			return BrandCompliance{}, fmt.Errorf("invalid brand color %q: %v", entry.Hex, err)
+		}
+		brandColors[i] = c
+	}
+
+	total := imageprocessor.TotalCount(colors)
+	var compliance BrandCompliance
+	for _, swatch := range imageprocessor.SortedSwatches(colors) {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return BrandCompliance{}, err
+		}
+		nearest, deltaE := 0, math.Inf(1)
+		for i, brandColor := range brandColors {
+			if d := c.DistanceCIEDE2000(brandColor) * 100; d < deltaE {
+				nearest, deltaE = i, d
+			}
+		}
+		if deltaE <= tolerance {
+			continue
+		}
+
+		coverage := 0.0
+		if total > 0 {
+			coverage = float64(swatch.Count) / float64(total)
+		}
+		compliance.OffBrand = append(compliance.OffBrand, OffBrandSwatch{Hex: swatch.Hex, Coverage: coverage, Nearest: brand[nearest], DeltaE: deltaE})
+		compliance.OffBrandCoverage += coverage
+	}
+	return compliance, nil
+}
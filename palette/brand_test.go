@@ -0,0 +1,126 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestCheckBrandComplianceTolerance(t *testing.T) {
+	brand := []BrandColor{{Name: "blue", Hex: "#1f77b4"}}
+	near := "#2a7fb8"
+	deltaE := mustHex(t, near).DistanceCIEDE2000(mustHex(t, brand[0].Hex)) * 100
+
+	tests := []struct {
+		name      string
+		tolerance float64
+		offBrand  bool
+	}{
+		{"within", deltaE + 0.01, false},
+		{"exactly at", deltaE, false},
+		{"just beyond", deltaE - 1e-9, true},
+		{"zero", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compliance, err := CheckBrandCompliance(map[string]int{near: 3, brand[0].Hex: 1}, brand, tt.tolerance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(compliance.OffBrand) == 1; got != tt.offBrand {
+				t.Fatalf("off-brand = %+v, want %s off-brand: %v", compliance.OffBrand, near, tt.offBrand)
+			}
+			if !tt.offBrand {
+				return
+			}
+			swatch := compliance.OffBrand[0]
+			if swatch.Hex != near || swatch.Nearest != brand[0] || swatch.Coverage != 0.75 || math.Abs(swatch.DeltaE-deltaE) > 1e-9 {
+				t.Errorf("off-brand swatch = %+v", swatch)
+			}
+			if compliance.OffBrandCoverage != 0.75 {
+				t.Errorf("off-brand coverage = %v, want 0.75", compliance.OffBrandCoverage)
+			}
+		})
+	}
+}
+
+func TestCheckBrandComplianceErrors(t *testing.T) {
+	colors := map[string]int{"#000000": 1}
+	if _, err := CheckBrandCompliance(colors, nil, 5); err == nil {
+		t.Error("empty brand palette accepted")
+	}
+	if _, err := CheckBrandCompliance(colors, []BrandColor{{Hex: "#zzzzzz"}}, 5); err == nil {
+		t.Error("invalid brand color accepted")
+	}
+}
+
+func TestCheckBrandComplianceCoverage(t *testing.T) {
+	// 90% of the image is many shades of the brand blue and 10% a single off-brand orange, so
+	// the orange must cover 10% however few distinct colors it has
+	img := image.NewRGBA(image.Rect(0, 0, 100, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 100; x++ {
+			c := color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 255}
+			if x >= 90 {
+				c = color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 255}
+			} else {
+				c.R += uint8(x % 5)
+				c.B += uint8(y % 4)
+			}
+			img.Set(x, y, c)
+		}
+	}
+	histogram, err := imageprocessor.ColorExtractor{}.Process(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quantized, err := imageprocessor.KMeansQuantizer{Seed: 1}.Quantize(histogram, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compliance, err := CheckBrandCompliance(quantized, []BrandColor{{Hex: "#1f77b4"}}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compliance.OffBrand) != 1 || compliance.OffBrand[0].Hex != "#ff7f0e" {
+		t.Fatalf("off-brand = %+v, want #ff7f0e", compliance.OffBrand)
+	}
+	if math.Abs(compliance.OffBrandCoverage-0.1) > 1e-9 {
+		t.Errorf("off-brand coverage = %v, want 0.1", compliance.OffBrandCoverage)
+	}
+}
+
+func TestCheckBrandComplianceTransparentAsset(t *testing.T) {
+	// A logo on a transparent canvas: 90% of the pixels are fully transparent, some with color
+	// data left under them, and the visible ones are the brand red
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 100; x++ {
+			switch {
+			case x >= 90:
+				img.SetNRGBA(x, y, color.NRGBA{R: 0xd6, G: 0x27, B: 0x28, A: 255})
+			case x%2 == 0:
+				img.SetNRGBA(x, y, color.NRGBA{R: 0x30, G: 0xc0, B: 0x40, A: 0})
+			}
+		}
+	}
+	histogram, err := imageprocessor.ColorExtractor{}.Process(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quantized, err := imageprocessor.KMeansQuantizer{Seed: 1}.Quantize(histogram, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compliance, err := CheckBrandCompliance(quantized, []BrandColor{{Name: "red", Hex: "#d62728"}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compliance.OffBrand) != 0 || compliance.OffBrandCoverage != 0 {
+		t.Errorf("off-brand = %+v (%.0f%%), want the transparent pixels ignored", compliance.OffBrand, compliance.OffBrandCoverage*100)
+	}
+}
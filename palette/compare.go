@@ -0,0 +1,187 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// emdScale is the number of units total coverage is split into when solving the transport problem
+const emdScale = 1_000_000
+
+// SwatchMatch pairs a color of one palette with the nearest color of another
+type SwatchMatch struct {
+	A, B      string
+	CoverageA float64 // Share of palette A's counts, from 0 to 1
+	CoverageB float64 // Share of palette B's counts, from 0 to 1
+	DeltaE    float64 // CIEDE2000 difference
+}
+
+// PaletteComparison describes how far apart two palettes are
+type PaletteComparison struct {
+	EMD     float64       // Earth mover's distance in Lab units, weighted by coverage
+	Matches []SwatchMatch // Every color of A, most frequent first, with its nearest color in B
+}
+
+// ComparePalettes computes the earth mover's distance between two palettes and matches each color
+// of the first with its nearest color in the second
+func ComparePalettes(a, b map[string]int) (PaletteComparison, error) {
+	emd, err := EarthMoversDistance(a, b)
+	if err != nil {
+		return PaletteComparison{}, err
+	}
+
+	weightedA, err := weightedColors(a)
+	if err != nil {
+		return PaletteComparison{}, err
+	}
+	weightedB, err := weightedColors(b)
+	if err != nil {
+		return PaletteComparison{}, err
+	}
+
+	comparison := PaletteComparison{EMD: emd}
+	for _, ca := range weightedA {
+		best, bestDistance := 0, math.Inf(1)
+		for j, cb := range weightedB {
+			if distance := ca.color.DistanceCIEDE2000(cb.color) * 100; distance < bestDistance {
+				best, bestDistance = j, distance
+			}
+		}
+		comparison.Matches = append(comparison.Matches, SwatchMatch{
+			A:         ca.hex,
+			B:         weightedB[best].hex,
+			CoverageA: ca.weight,
+			CoverageB: weightedB[best].weight,
+			DeltaE:    bestDistance,
+		})
+	}
+	return comparison, nil
+}
+
+// EarthMoversDistance returns the minimum average Lab distance, in 0–100 lightness units, that
+// coverage has to move to turn one palette into the other. Identical palettes are 0 apart.
+func EarthMoversDistance(a, b map[string]int) (float64, error) {
+	weightedA, err := weightedColors(a)
+	if err != nil {
+		return 0, err
+	}
+	weightedB, err := weightedColors(b)
+	if err != nil {
+		return 0, err
+	}
+
+	supply, demand := scaledWeights(weightedA), scaledWeights(weightedB)
+	costs := make([][]float64, len(weightedA))
+	for i, ca := range weightedA {
+		costs[i] = make([]float64, len(weightedB))
+		for j, cb := range weightedB {
+			costs[i][j] = ca.color.DistanceLab(cb.color) * 100
+		}
+	}
+	return transportCost(supply, demand, costs) / emdScale, nil
+}
+
+type weightedColor struct {
+	hex    string
+	color  colorful.Color
+	weight float64
+}
+
+// weightedColors parses a palette into colors with their share of the counts, most frequent first
+func weightedColors(colors map[string]int) ([]weightedColor, error) {
+	total := imageprocessor.TotalCount(colors)
+	if total <= 0 {
+		return nil, fmt.Errorf("palette is empty")
+	}
+	var weighted []weightedColor
+	for _, swatch := range imageprocessor.SortedSwatches(colors) {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return nil, err
+		}
+		weighted = append(weighted, weightedColor{hex: swatch.Hex, color: c, weight: float64(swatch.Count) / float64(total)})
+	}
+	return weighted, nil
+}
+
+// scaledWeights converts weights into integers summing to exactly emdScale
+func scaledWeights(colors []weightedColor) []int {
+	scaled := make([]int, len(colors))
+	sum := 0
+	for i, c := range colors {
+		scaled[i] = int(math.Round(c.weight * emdScale))
+		sum += scaled[i]
+	}
+	// Rounding leftovers go to the most frequent color
+	scaled[0] += emdScale - sum
+	return scaled
+}
+
+// transportCost solves the balanced transportation problem by successive shortest paths on the
+// residual graph, returning the total cost of the cheapest flow from supply to demand
+func transportCost(supply, demand []int, costs [][]float64) float64 {
+	n, m := len(supply), len(demand)
+	// Nodes: source, supply nodes, demand nodes, sink
+	source, sink := 0, n+m+1
+	type edge struct {
+		to, capacity, reverse int
+		cost                  float64
+	}
+	graph := make([][]edge, n+m+2)
+	addEdge := func(from, to, capacity int, cost float64) {
+		graph[from] = append(graph[from], edge{to: to, capacity: capacity, cost: cost, reverse: len(graph[to])})
+		graph[to] = append(graph[to], edge{to: from, capacity: 0, cost: -cost, reverse: len(graph[from]) - 1})
+	}
+	for i, s := range supply {
+		addEdge(source, 1+i, s, 0)
+		for j := range demand {
+			addEdge(1+i, 1+n+j, math.MaxInt32, costs[i][j])
+		}
+	}
+	for j, d := range demand {
+		addEdge(1+n+j, sink, d, 0)
+	}
+
+	total := 0.0
+	for {
+		// Bellman-Ford, as residual edges have negative costs
+		distance := make([]float64, len(graph))
+		for i := range distance {
+			distance[i] = math.Inf(1)
+		}
+		previous := make([][2]int, len(graph))
+		distance[source] = 0
+		for changed := true; changed; {
+			changed = false
+			for node := range graph {
+				if math.IsInf(distance[node], 1) {
+					continue
+				}
+				for k, e := range graph[node] {
+					if e.capacity > 0 && distance[node]+e.cost < distance[e.to]-1e-12 {
+						distance[e.to] = distance[node] + e.cost
+						previous[e.to] = [2]int{node, k}
+						changed = true
+					}
+				}
+			}
+		}
+		if math.IsInf(distance[sink], 1) {
+			return total
+		}
+
+		flow := math.MaxInt
+		for node := sink; node != source; node = previous[node][0] {
+			flow = min(flow, graph[previous[node][0]][previous[node][1]].capacity)
+		}
+		for node := sink; node != source; node = previous[node][0] {
+			e := &graph[previous[node][0]][previous[node][1]]
+			e.capacity -= flow
+			graph[e.to][e.reverse].capacity += flow
+		}
+		total += float64(flow) * distance[sink]
+	}
+}
@@ -0,0 +1,67 @@
+package palette
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEarthMoversDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b map[string]int
+		want float64
+	}{
+		{"identical", map[string]int{"#ff0000": 3, "#00ff00": 1}, map[string]int{"#ff0000": 3, "#00ff00": 1}, 0},
+		{"same shares", map[string]int{"#ff0000": 3, "#00ff00": 1}, map[string]int{"#ff0000": 300, "#00ff00": 100}, 0},
+		{"black to white", map[string]int{"#000000": 1}, map[string]int{"#ffffff": 5}, 100},
+		// Half the coverage moves from white to black
+		{"half moves", map[string]int{"#000000": 1, "#ffffff": 1}, map[string]int{"#000000": 1}, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EarthMoversDistance(tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("EarthMoversDistance = %.4f, want %.4f", got, tt.want)
+			}
+			reverse, err := EarthMoversDistance(tt.b, tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-reverse) > 1e-3 {
+				t.Errorf("EarthMoversDistance is not symmetric: %.4f and %.4f", got, reverse)
+			}
+		})
+	}
+
+	if _, err := EarthMoversDistance(map[string]int{}, map[string]int{"#000000": 1}); err == nil {
+		t.Error("EarthMoversDistance of an empty palette succeeded")
+	}
+}
+
+func TestComparePalettes(t *testing.T) {
+	a := map[string]int{"#ff0000": 3, "#000000": 1}
+	b := map[string]int{"#fe0000": 1, "#ffffff": 1, "#101010": 2}
+	comparison, err := ComparePalettes(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SwatchMatch{
+		{A: "#ff0000", B: "#fe0000", CoverageA: 0.75, CoverageB: 0.25},
+		{A: "#000000", B: "#101010", CoverageA: 0.25, CoverageB: 0.5},
+	}
+	if len(comparison.Matches) != len(want) {
+		t.Fatalf("got %d matches, want %d", len(comparison.Matches), len(want))
+	}
+	for i, match := range comparison.Matches {
+		w := want[i]
+		if match.A != w.A || match.B != w.B || match.CoverageA != w.CoverageA || match.CoverageB != w.CoverageB {
+			t.Errorf("match %d = %+v, want %+v", i, match, w)
+		}
+		if wantDeltaE := mustHex(t, w.A).DistanceCIEDE2000(mustHex(t, w.B)) * 100; math.Abs(match.DeltaE-wantDeltaE) > 1e-9 {
+			t.Errorf("match %d ΔE = %.4f, want %.4f", i, match.DeltaE, wantDeltaE)
+		}
+	}
+}
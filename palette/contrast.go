@@ -0,0 +1,198 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// RelativeLuminance returns the WCAG 2.x relative luminance of a color, from 0 (black) to 1 (white)
+func RelativeLuminance(c colorful.Color) float64 {
+	r, g, b := c.Clamped().LinearRgb()
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// ContrastRatio returns the WCAG 2.x contrast ratio between two colors, from 1 to 21
+func ContrastRatio(a, b colorful.Color) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	lighter, darker := math.Max(la, lb), math.Min(la, lb)
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// AdjustLightnessForContrast returns the color closest in HCL lightness to c, with hue and chroma
+// unchanged, whose contrast ratio against the other color is at least minRatio. It reports false
+// if no lightness reaches the ratio.
+func AdjustLightnessForContrast(c, against colorful.Color, minRatio float64) (colorful.Color, bool) {
+	if ContrastRatio(c, against) >= minRatio {
+		return c, true
+	}
+
+	h, chroma, l := c.Hcl()
+	var best colorful.Color
+	bestDelta := math.Inf(1)
+
+	for _, target := range []float64{0, 1} {
+		extreme := colorful.Hcl(h, chroma, target).Clamped()
+		if ContrastRatio(extreme, against) < minRatio {
+			continue
+		}
+		// Contrast grows as lightness moves toward the extreme, so bisect for the closest passing lightness
+		lo, hi := l, target
+		for i := 0; i < 30; i++ {
+			mid := (lo + hi) / 2
+			if ContrastRatio(colorful.Hcl(h, chroma, mid).Clamped(), against) >= minRatio {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+		if delta := math.Abs(hi - l); delta < bestDelta {
+			best, bestDelta = colorful.Hcl(h, chroma, hi).Clamped(), delta
+		}
+	}
+
+	if math.IsInf(bestDelta, 1) {
+		return c, false
+	}
+	return best, true
+}
+
+// WCAG 2.x minimum contrast ratios
+const (
+	WCAGAANormal  = 4.5 // AA for normal text
+	WCAGAALarge   = 3.0 // AA for large text (18pt, or 14pt bold)
+	WCAGAAANormal = 7.0 // AAA for normal text
+	WCAGAAALarge  = 4.5 // AAA for large text
+)
+
+// APCAContrast returns the APCA lightness contrast Lc (0.0.98G-4g constants) of text on a background,
+// from about -108 to 106. It is positive for dark text on a light background and negative for light
+// text on a dark one; its magnitude is what matters for readability.
+func APCAContrast(text, background colorful.Color) float64 {
+	const (
+		blackThreshold = 0.022
+		blackClamp     = 1.414
+		deltaYMin      = 0.0005
+		scale          = 1.14
+		offset         = 0.027
+		lowClip        = 0.1
+	)
+	screenLuminance := func(c colorful.Color) float64 {
+		c = c.Clamped()
+		y := 0.2126729*math.Pow(c.R, 2.4) + 0.7151522*math.Pow(c.G, 2.4) + 0.0721750*math.Pow(c.B, 2.4)
+		if y < blackThreshold {
+			y += math.Pow(blackThreshold-y, blackClamp)
+		}
+		return y
+	}
+
+	yText, yBackground := screenLuminance(text), screenLuminance(background)
+	if math.Abs(yBackground-yText) < deltaYMin {
+		return 0
+	}
+
+	if yBackground > yText {
+		// Dark text on a light background
+		sapc := (math.Pow(yBackground, 0.56) - math.Pow(yText, 0.57)) * scale
+		if sapc < lowClip {
+			return 0
+		}
+		return (sapc - offset) * 100
+	}
+	// Light text on a dark background
+	sapc := (math.Pow(yBackground, 0.65) - math.Pow(yText, 0.62)) * scale
+	if sapc > -lowClip {
+		return 0
+	}
+	return (sapc + offset) * 100
+}
+
+// ContrastPair is the contrast between two palette colors, the less frequent one taken as text
+// on the more frequent one
+type ContrastPair struct {
+	Foreground string
+	Background string
+	Ratio      float64 // WCAG 2.x contrast ratio
+	APCA       float64 // APCA Lc of the foreground on the background
+	AANormal   bool
+	AALarge    bool
+	AAANormal  bool
+	AAALarge   bool
+
+	// For pairs below the required ratio, the smallest lightness change of either color that
+	// reaches it. At most one of the suggested colors is set; both are empty if none is needed or possible.
+	SuggestedForeground string
+	SuggestedBackground string
+	SuggestedRatio      float64
+}
+
+// AnalyzeContrast computes the contrast of every pair of palette colors, ordered by frequency, and
+// suggests adjustments for the pairs below minRatio
+func AnalyzeContrast(colors map[string]int, minRatio float64) []ContrastPair {
+	swatches := imageprocessor.SortedSwatches(colors)
+	parsed := make([]colorful.Color, 0, len(swatches))
+	hexes := make([]string, 0, len(swatches))
+	for _, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, c)
+		hexes = append(hexes, swatch.Hex)
+	}
+
+	var pairs []ContrastPair
+	for i := range parsed {
+		for j := i + 1; j < len(parsed); j++ {
+			background, foreground := parsed[i], parsed[j]
+			ratio := ContrastRatio(foreground, background)
+			pair := ContrastPair{
+				Foreground: hexes[j],
+				Background: hexes[i],
+				Ratio:      ratio,
+				APCA:       APCAContrast(foreground, background),
+				AANormal:   ratio >= WCAGAANormal,
+				AALarge:    ratio >= WCAGAALarge,
+				AAANormal:  ratio >= WCAGAAANormal,
+				AAALarge:   ratio >= WCAGAAALarge,
+			}
+			if ratio < minRatio {
+				suggestContrast(&pair, foreground, background, minRatio)
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// suggestContrast adjusts whichever color of the pair needs the smaller change to reach minRatio
+func suggestContrast(pair *ContrastPair, foreground, background colorful.Color, minRatio float64) {
+	bestDistance := math.Inf(1)
+	if adjusted, ok := adjustForHex(foreground, background, minRatio); ok {
+		bestDistance = foreground.DistanceCIEDE2000(adjusted)
+		pair.SuggestedForeground = adjusted.Hex()
+		pair.SuggestedRatio = ContrastRatio(adjusted, background)
+	}
+	if adjusted, ok := adjustForHex(background, foreground, minRatio); ok && background.DistanceCIEDE2000(adjusted) < bestDistance {
+		pair.SuggestedForeground = ""
+		pair.SuggestedBackground = adjusted.Hex()
+		pair.SuggestedRatio = ContrastRatio(foreground, adjusted)
+	}
+}
+
+// adjustForHex is AdjustLightnessForContrast for colors written as 8-bit hex codes: it aims slightly
+// higher until rounding to hex no longer drops the ratio below minRatio
+func adjustForHex(c, against colorful.Color, minRatio float64) (colorful.Color, bool) {
+	for target := minRatio; target < minRatio+0.1; target += 0.01 {
+		adjusted, ok := AdjustLightnessForContrast(c, against, target)
+		if !ok {
+			return c, false
+		}
+		rounded, err := colorful.Hex(adjusted.Hex())
+		if err == nil && ContrastRatio(rounded, against) >= minRatio {
+			return rounded, true
+		}
+	}
+	return c, false
+}
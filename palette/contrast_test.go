@@ -0,0 +1,100 @@
+package palette
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+func mustHex(t *testing.T, hex string) colorful.Color {
+	t.Helper()
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"#000000", "#ffffff", 21},
+		{"#ffffff", "#000000", 21},
+		{"#777777", "#777777", 1},
+		{"#767676", "#ffffff", 4.54}, // The darkest gray passing AA on white
+		{"#777777", "#ffffff", 4.48},
+		{"#ff0000", "#ffffff", 4.00},
+		{"#0000ff", "#000000", 2.44},
+	}
+	for _, tt := range tests {
+		if got := ContrastRatio(mustHex(t, tt.a), mustHex(t, tt.b)); math.Abs(got-tt.want) > 0.005 {
+			t.Errorf("ContrastRatio(%s, %s) = %.4f, want %.2f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAPCAContrast(t *testing.T) {
+	// Reference values of the APCA 0.0.98G-4g calculator
+	tests := []struct {
+		text, background string
+		want             float64
+	}{
+		{"#888888", "#ffffff", 63.06},
+		{"#ffffff", "#888888", -68.54},
+		{"#000000", "#ffffff", 106.04},
+		{"#ffffff", "#000000", -107.88},
+		{"#777777", "#777777", 0},
+	}
+	for _, tt := range tests {
+		if got := APCAContrast(mustHex(t, tt.text), mustHex(t, tt.background)); math.Abs(got-tt.want) > 0.05 {
+			t.Errorf("APCAContrast(%s on %s) = %.3f, want %.2f", tt.text, tt.background, got, tt.want)
+		}
+	}
+}
+
+func TestAdjustLightnessForContrast(t *testing.T) {
+	white := mustHex(t, "#ffffff")
+	for _, hex := range []string{"#ff8800", "#88ccff", "#bbbbbb", "#ffff00"} {
+		c := mustHex(t, hex)
+		adjusted, ok := AdjustLightnessForContrast(c, white, WCAGAANormal)
+		if !ok {
+			t.Errorf("%s: no lightness reaches %g against white", hex, WCAGAANormal)
+			continue
+		}
+		// Just past the ratio: bisection stops at the closest passing lightness
+		if ratio := ContrastRatio(adjusted, white); ratio < WCAGAANormal || ratio > WCAGAANormal+0.05 {
+			t.Errorf("%s adjusted to %s has ratio %.3f, want just above %g", hex, adjusted.Hex(), ratio, WCAGAANormal)
+		}
+		// Clamping saturated colors back into sRGB shifts their hue a little
+		h, _, _ := c.Hcl()
+		if ah, _, _ := adjusted.Hcl(); HueDistance(h, ah) > 10 {
+			t.Errorf("%s adjusted to %s changed hue from %.1f to %.1f", hex, adjusted.Hex(), h, ah)
+		}
+	}
+
+	if _, ok := AdjustLightnessForContrast(mustHex(t, "#808080"), mustHex(t, "#808080"), 22); ok {
+		t.Error("a ratio above 21 was reached")
+	}
+}
+
+func TestAnalyzeContrastSuggestions(t *testing.T) {
+	pairs := AnalyzeContrast(map[string]int{"#ffffff": 10, "#999999": 5, "#000000": 1}, WCAGAANormal)
+	if len(pairs) != 3 {
+		t.Fatalf("got %d pairs, want 3", len(pairs))
+	}
+	for _, pair := range pairs {
+		if pair.Ratio >= WCAGAANormal {
+			if pair.SuggestedForeground != "" || pair.SuggestedBackground != "" {
+				t.Errorf("%s on %s passes but has a suggestion", pair.Foreground, pair.Background)
+			}
+			continue
+		}
+		suggested := pair.SuggestedForeground + pair.SuggestedBackground
+		if suggested == "" || pair.SuggestedRatio < WCAGAANormal {
+			t.Errorf("%s on %s: suggestion %q with ratio %.2f, want one reaching %g", pair.Foreground, pair.Background, suggested, pair.SuggestedRatio, WCAGAANormal)
+		}
+	}
+}
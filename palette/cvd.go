@@ -0,0 +1,214 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Color vision deficiencies
+const (
+	CVDProtanopia    = "protanopia"    // No long-wavelength (red) cones
+	CVDDeuteranopia  = "deuteranopia"  // No medium-wavelength (green) cones
+	CVDTritanopia    = "tritanopia"    // No short-wavelength (blue) cones
+	CVDAchromatopsia = "achromatopsia" // No color vision, only luminance
+)
+
+// CVDConditions lists the color vision deficiencies that can be simulated
+var CVDConditions = []string{CVDProtanopia, CVDDeuteranopia, CVDTritanopia, CVDAchromatopsia}
+
+// Simulation models of color vision deficiencies
+const (
+	CVDBrettel = "brettel" // Brettel, Viénot & Mollon (1997), two half-planes in LMS
+	CVDVienot  = "vienot"  // Viénot, Brettel & Mollon (1999), a single plane; inaccurate for tritanopia
+	CVDMachado = "machado" // Machado, Oliveira & Fernandes (2009) at full severity
+)
+
+// CVDMethods lists the supported simulation models
+var CVDMethods = []string{CVDBrettel, CVDVienot, CVDMachado}
+
+// cvdMatrix maps linear RGB to the linear RGB perceived with a deficiency
+type cvdMatrix [3][3]float64
+
+func (m cvdMatrix) apply(v [3]float64) [3]float64 {
+	var out [3]float64
+	for i := range out {
+		out[i] = m[i][0]*v[0] + m[i][1]*v[1] + m[i][2]*v[2]
+	}
+	return out
+}
+
+// brettelModel picks one of two projections depending on the side of a separation plane
+type brettelModel struct {
+	first, second cvdMatrix
+	normal        [3]float64
+}
+
+// Simulation matrices in linear RGB, as derived for sRGB by DaltonLens
+var (
+	machadoMatrices = map[string]cvdMatrix{
+		CVDProtanopia:   {{0.152286, 1.052583, -0.204868}, {0.114503, 0.786281, 0.099216}, {-0.003882, -0.048116, 1.051998}},
+		CVDDeuteranopia: {{0.367322, 0.860646, -0.227968}, {0.280085, 0.672501, 0.047413}, {-0.011820, 0.042940, 0.968881}},
+		CVDTritanopia:   {{1.255528, -0.076749, -0.178779}, {-0.078411, 0.930809, 0.147602}, {0.004733, 0.691367, 0.303900}},
+	}
+	vienotMatrices = map[string]cvdMatrix{
+		CVDProtanopia:   {{0.11238, 0.88762, 0}, {0.11238, 0.88762, 0}, {0.00401, -0.00401, 1}},
+		CVDDeuteranopia: {{0.29275, 0.70725, 0}, {0.29275, 0.70725, 0}, {-0.02234, 0.02234, 1}},
+		CVDTritanopia:   {{1, 0.14461, -0.14461}, {0, 0.85924, 0.14076}, {0, 0.85924, 0.14076}},
+	}
+	brettelModels = map[string]brettelModel{
+		CVDProtanopia: {
+			first:  cvdMatrix{{0.14980, 1.19548, -0.34528}, {0.10764, 0.84864, 0.04372}, {0.00384, -0.00540, 1.00156}},
+			second: cvdMatrix{{0.14570, 1.16172, -0.30742}, {0.10816, 0.85291, 0.03892}, {0.00386, -0.00524, 1.00139}},
+			normal: [3]float64{0.00048, 0.00393, -0.00441},
+		},
+		CVDDeuteranopia: {
+			first:  cvdMatrix{{0.36477, 0.86381, -0.22858}, {0.26294, 0.64245, 0.09462}, {-0.02006, 0.02728, 0.99278}},
+			second: cvdMatrix{{0.37298, 0.88166, -0.25464}, {0.25954, 0.63506, 0.10540}, {-0.01980, 0.02784, 0.99196}},
+			normal: [3]float64{-0.00281, -0.00611, 0.00892},
+		},
+		CVDTritanopia: {
+			first:  cvdMatrix{{1.01277, 0.13548, -0.14826}, {-0.01243, 0.86812, 0.14431}, {0.07589, 0.80500, 0.11911}},
+			second: cvdMatrix{{0.93678, 0.18979, -0.12657}, {0.06154, 0.81526, 0.12320}, {-0.37562, 1.12767, 1.24796}},
+			normal: [3]float64{0.03901, -0.02788, -0.01113},
+		},
+	}
+)
+
+// CVDSimulator converts colors to how they appear with one color vision deficiency
+type CVDSimulator struct {
+	condition string
+	simulate  func(v [3]float64) [3]float64
+}
+
+// NewCVDSimulator returns a simulator for the condition using the given model. Achromatopsia is
+// the same in every model: colors are reduced to their luminance.
+func NewCVDSimulator(condition, method string) (*CVDSimulator, error) {
+	if method == "" {
+		method = CVDMachado
+	}
+	if method != CVDBrettel && method != CVDVienot && method != CVDMachado {
+		return nil, fmt.Errorf("invalid simulation method: %s. Supported methods: %s", method, strings.Join(CVDMethods, ", "))
+	}
+	var simulate func(v [3]float64) [3]float64
+	switch {
+	case condition == CVDAchromatopsia:
+		simulate = func(v [3]float64) [3]float64 {
+			y := 0.2126*v[0] + 0.7152*v[1] + 0.0722*v[2]
+			return [3]float64{y, y, y}
+		}
+	case method == CVDMachado || method == CVDVienot:
+		matrices := machadoMatrices
+		if method == CVDVienot {
+			matrices = vienotMatrices
+		}
+		matrix, ok := matrices[condition]
+		if !ok {
+			return nil, fmt.Errorf("invalid color vision deficiency: %s. Supported conditions: %s", condition, strings.Join(CVDConditions, ", "))
+		}
+		simulate = matrix.apply
+	default:
+		model, ok := brettelModels[condition]
+		if !ok {
+			return nil, fmt.Errorf("invalid color vision deficiency: %s. Supported conditions: %s", condition, strings.Join(CVDConditions, ", "))
+		}
+		simulate = func(v [3]float64) [3]float64 {
+			if v[0]*model.normal[0]+v[1]*model.normal[1]+v[2]*model.normal[2] >= 0 {
+				return model.first.apply(v)
+			}
+			return model.second.apply(v)
+		}
+	}
+	return &CVDSimulator{condition: condition, simulate: simulate}, nil
+}
+
+// Simulate returns the color as perceived with the deficiency
+func (s *CVDSimulator) Simulate(c colorful.Color) colorful.Color {
+	r, g, b := c.Clamped().LinearRgb()
+	v := s.simulate([3]float64{r, g, b})
+	return colorful.LinearRgb(clamp(v[0], 0, 1), clamp(v[1], 0, 1), clamp(v[2], 0, 1))
+}
+
+// SimulatePalette returns the palette as perceived with the deficiency. Colors that become the
+// same have their counts merged.
+func (s *CVDSimulator) SimulatePalette(colors map[string]int) map[string]int {
+	simulated := make(map[string]int, len(colors))
+	for hex, count := range colors {
+		c, err := colorful.Hex(hex)
+		if err != nil {
+			continue
+		}
+		simulated[s.Simulate(c).Hex()] += count
+	}
+	return simulated
+}
+
+// SimulateImage returns the image as perceived with the deficiency, keeping its alpha
+func (s *CVDSimulator) SimulateImage(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	cache := make(map[uint32]color.NRGBA)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Simulate the straight color; premultiplied values would darken translucent pixels
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			key := uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+			simulated, ok := cache[key]
+			if !ok {
+				sc := s.Simulate(colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255})
+				simulated.R, simulated.G, simulated.B = sc.RGB255()
+				cache[key] = simulated
+			}
+			simulated.A = c.A
+			out.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, simulated)
+		}
+	}
+	return out
+}
+
+// ConfusedPair is a pair of palette colors that become hard to tell apart with a deficiency
+type ConfusedPair struct {
+	Condition  string
+	A, B       string  // Original colors
+	SimulatedA string  // A as perceived with the deficiency
+	SimulatedB string  // B as perceived with the deficiency
+	DeltaE     float64 // CIEDE2000 difference of the perceived colors
+}
+
+// FindConfusedPairs returns the pairs of palette colors whose CIEDE2000 difference drops below
+// threshold with the deficiency, most frequent colors first
+func (s *CVDSimulator) FindConfusedPairs(colors map[string]int, threshold float64) []ConfusedPair {
+	swatches := imageprocessor.SortedSwatches(colors)
+	simulated := make([]colorful.Color, len(swatches))
+	valid := make([]bool, len(swatches))
+	for i, swatch := range swatches {
+		if c, err := colorful.Hex(swatch.Hex); err == nil {
+			simulated[i], valid[i] = s.Simulate(c), true
+		}
+	}
+
+	var pairs []ConfusedPair
+	for i := range swatches {
+		for j := i + 1; j < len(swatches); j++ {
+			if !valid[i] || !valid[j] {
+				continue
+			}
+			deltaE := simulated[i].DistanceCIEDE2000(simulated[j]) * 100
+			if deltaE < threshold {
+				pairs = append(pairs, ConfusedPair{
+					Condition:  s.condition,
+					A:          swatches[i].Hex,
+					B:          swatches[j].Hex,
+					SimulatedA: simulated[i].Hex(),
+					SimulatedB: simulated[j].Hex(),
+					DeltaE:     deltaE,
+				})
+			}
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,103 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+func TestCVDNeutralsUnchanged(t *testing.T) {
+	// Every model keeps the white point, so grays look the same with any deficiency
+	for _, method := range CVDMethods {
+		for _, condition := range CVDConditions {
+			simulator, err := NewCVDSimulator(condition, method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, hex := range []string{"#000000", "#808080", "#ffffff"} {
+				c, _ := colorful.Hex(hex)
+				if got := simulator.Simulate(c); got.DistanceRgb(c) > 0.01 {
+					t.Errorf("%s/%s: %s became %s", method, condition, hex, got.Hex())
+				}
+			}
+		}
+	}
+}
+
+func TestCVDPrimaries(t *testing.T) {
+	// A primary selects one column of the matrix in linear RGB
+	red, green, blue := colorful.Color{R: 1}, colorful.Color{G: 1}, colorful.Color{B: 1}
+	for condition, matrix := range machadoMatrices {
+		simulator, err := NewCVDSimulator(condition, CVDMachado)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for column, primary := range []colorful.Color{red, green, blue} {
+			want := colorful.LinearRgb(clamp(matrix[0][column], 0, 1), clamp(matrix[1][column], 0, 1), clamp(matrix[2][column], 0, 1))
+			if got := simulator.Simulate(primary); got.Hex() != want.Hex() {
+				t.Errorf("%s: %s became %s, want %s", condition, primary.Hex(), got.Hex(), want.Hex())
+			}
+		}
+	}
+
+	// Protanopes see pure red as a dark olive, reference value of the Machado model
+	simulator, _ := NewCVDSimulator(CVDProtanopia, CVDMachado)
+	if got := simulator.Simulate(red).Hex(); got != "#6d5f00" {
+		t.Errorf("protanopia red = %s, want #6d5f00", got)
+	}
+}
+
+func TestCVDConfusions(t *testing.T) {
+	// Red against olive for protans and red against green for deutans are classic confusions,
+	// which tritans still tell apart
+	tests := []struct {
+		condition string
+		a, b      string
+		confused  bool
+	}{
+		{CVDProtanopia, "#cc3333", "#666600", true},
+		{CVDDeuteranopia, "#d62728", "#2ca02c", true},
+		{CVDTritanopia, "#cc3333", "#666600", false},
+		{CVDTritanopia, "#d62728", "#2ca02c", false},
+	}
+	for _, tt := range tests {
+		for _, method := range CVDMethods {
+			simulator, err := NewCVDSimulator(tt.condition, method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pairs := simulator.FindConfusedPairs(map[string]int{tt.a: 2, tt.b: 1}, 15)
+			if confused := len(pairs) == 1; confused != tt.confused {
+				t.Errorf("%s/%s: %s and %s confused = %v, want %v (%+v)", method, tt.condition, tt.a, tt.b, confused, tt.confused, pairs)
+			}
+		}
+	}
+}
+
+func TestSimulateImageAlpha(t *testing.T) {
+	simulator, err := NewCVDSimulator(CVDDeuteranopia, CVDMachado)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := image.NewNRGBA(image.Rect(5, 5, 8, 6))
+	pixels := []color.NRGBA{{R: 200, G: 40, B: 40, A: 255}, {R: 200, G: 40, B: 40, A: 64}, {}}
+	for i, c := range pixels {
+		img.SetNRGBA(5+i, 5, c)
+	}
+
+	out := simulator.SimulateImage(img)
+	want := simulator.Simulate(colorful.Color{R: 200.0 / 255, G: 40.0 / 255, B: 40.0 / 255})
+	wr, wg, wb := want.RGB255()
+	for i, c := range pixels {
+		got := out.NRGBAAt(i, 0)
+		if got.A != c.A {
+			t.Errorf("pixel %d alpha = %d, want %d", i, got.A, c.A)
+		}
+		// Translucent pixels look like opaque ones, only fainter
+		if c.A != 0 && (got.R != wr || got.G != wg || got.B != wb) {
+			t.Errorf("pixel %d = %v, want the simulated color %s", i, got, want.Hex())
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package palette
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Dithering methods
+const (
+	DitherNone           = "none"            // Plain nearest color, as Remap
+	DitherFloydSteinberg = "floyd-steinberg" // Error diffusion to 4 neighbours
+	DitherAtkinson       = "atkinson"        // Error diffusion of 3/4 of the error to 6 neighbours, for higher contrast
+	DitherSierra         = "sierra"          // Three-row error diffusion to 10 neighbours
+	DitherBayer2         = "bayer2"          // Ordered dithering with a 2x2 Bayer matrix
+	DitherBayer4         = "bayer4"          // Ordered dithering with a 4x4 Bayer matrix
+	DitherBayer8         = "bayer8"          // Ordered dithering with an 8x8 Bayer matrix
+)
+
+// DitherMethods lists the supported dithering methods
+var DitherMethods = []string{DitherNone, DitherFloydSteinberg, DitherAtkinson, DitherSierra, DitherBayer2, DitherBayer4, DitherBayer8}
+
+// Color spaces dithering works in
+const (
+	SpaceLinear = "linear" // Linear RGB
+	SpaceLab    = "lab"    // CIE Lab; ordered dithering only varies the lightness
+)
+
+// DitherSpaces lists the color spaces dithering can work in
+var DitherSpaces = []string{SpaceLinear, SpaceLab}
+
+// DitherOptions configures Dither
+type DitherOptions struct {
+	Method     string  // One of DitherMethods; empty means none
+	Serpentine bool    // Alternate the scan direction on every row of error diffusion
+	Strength   float64 // Fraction of the error or threshold applied, from 0 to 1
+	Space      string  // One of DitherSpaces; empty means linear
+}
+
+// diffusionWeight spreads part of a pixel's error to the neighbour at dx, dy
+type diffusionWeight struct {
+	dx, dy int
+	weight float64
+}
+
+// diffusionKernels holds the error diffusion methods, for scanning left to right
+var diffusionKernels = map[string][]diffusionWeight{
+	DitherFloydSteinberg: {
+		{1, 0, 7.0 / 16}, {-1, 1, 3.0 / 16}, {0, 1, 5.0 / 16}, {1, 1, 1.0 / 16},
+	},
+	DitherAtkinson: {
+		{1, 0, 1.0 / 8}, {2, 0, 1.0 / 8}, {-1, 1, 1.0 / 8}, {0, 1, 1.0 / 8}, {1, 1, 1.0 / 8}, {0, 2, 1.0 / 8},
+	},
+	DitherSierra: {
+		{1, 0, 5.0 / 32}, {2, 0, 3.0 / 32},
+		{-2, 1, 2.0 / 32}, {-1, 1, 4.0 / 32}, {0, 1, 5.0 / 32}, {1, 1, 4.0 / 32}, {2, 1, 2.0 / 32},
+		{-1, 2, 2.0 / 32}, {0, 2, 3.0 / 32}, {1, 2, 2.0 / 32},
+	},
+}
+
+// bayerSizes maps the ordered dithering methods to the size of their matrix
+var bayerSizes = map[string]int{DitherBayer2: 2, DitherBayer4: 4, DitherBayer8: 8}
+
+// Dither reduces img to the palette colors, dithering to hide the banding of plain remapping
+func Dither(img image.Image, colors map[string]int, opts DitherOptions) (*image.Paletted, error) {
+	if opts.Method == "" || opts.Method == DitherNone {
+		return Remap(img, colors)
+	}
+	if opts.Strength < 0 || opts.Strength > 1 {
+		return nil, fmt.Errorf("invalid dither strength: %g. It must be between 0 and 1", opts.Strength)
+	}
+	space := opts.Space
+	if space == "" {
+		space = SpaceLinear
+	}
+	if space != SpaceLinear && space != SpaceLab {
+		return nil, fmt.Errorf("invalid dither space: %s. Supported spaces: %s", space, strings.Join(DitherSpaces, ", "))
+	}
+
+	pal, err := newRemapPalette(colors, img)
+	if err != nil {
+		return nil, err
+	}
+	d := &ditherer{matcher: newNearestMatcher(pal.colors), space: space, palette: pal}
+	d.entries = make([][3]float64, len(pal.colors))
+	for i, entry := range pal.colors {
+		c, _ := colorful.MakeColor(entry)
+		d.entries[i] = d.toSpace(c)
+	}
+
+	// Colors are dithered without their alpha, which is kept as is
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	pixels := make([][3]float64, width*height)
+	d.alphas = make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			pixels[y*width+x] = d.toSpace(colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255})
+			d.alphas[y*width+x] = c.A
+		}
+	}
+
+	out := image.NewPaletted(image.Rect(0, 0, width, height), pal.entries)
+	if kernel, ok := diffusionKernels[opts.Method]; ok {
+		d.diffuse(out, pixels, kernel, opts)
+	} else if size, ok := bayerSizes[opts.Method]; ok {
+		d.ordered(out, pixels, bayerMatrix(size), len(pal.colors), opts.Strength)
+	} else {
+		return nil, fmt.Errorf("invalid dither method: %s. Supported methods: %s", opts.Method, strings.Join(DitherMethods, ", "))
+	}
+	return out, nil
+}
+
+// ditherer holds the palette in the working color space and the alpha of the pixels
+type ditherer struct {
+	matcher *nearestMatcher
+	space   string
+	entries [][3]float64
+	palette *remapPalette
+	alphas  []uint8
+}
+
+// toSpace converts a color into the working space
+func (d *ditherer) toSpace(c colorful.Color) [3]float64 {
+	if d.space == SpaceLab {
+		l, a, b := c.Lab()
+		return [3]float64{l, a, b}
+	}
+	r, g, b := c.LinearRgb()
+	return [3]float64{r, g, b}
+}
+
+// nearest returns the palette index perceptually nearest to a color in the working space
+func (d *ditherer) nearest(v [3]float64) uint8 {
+	if d.space == SpaceLab {
+		return d.matcher.nearestLab(clamp(v[0], 0, 1), v[1], v[2])
+	}
+	l, a, b := colorful.LinearRgb(clamp(v[0], 0, 1), clamp(v[1], 0, 1), clamp(v[2], 0, 1)).Lab()
+	return d.matcher.nearestLab(l, a, b)
+}
+
+// nearestInSpace returns the palette index nearest to a color by distance in the working space.
+// Ordered dithering needs it to stay unbiased: the thresholds are spread evenly in that space,
+// so the choice between two colors must flip halfway between them there.
+func (d *ditherer) nearestInSpace(v [3]float64) uint8 {
+	best, bestDistance := 0, math.Inf(1)
+	for i, entry := range d.entries {
+		d0, d1, d2 := v[0]-entry[0], v[1]-entry[1], v[2]-entry[2]
+		if distance := d0*d0 + d1*d1 + d2*d2; distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return uint8(best)
+}
+
+// diffuse spreads the quantization error of each pixel to the neighbours not visited yet
+func (d *ditherer) diffuse(out *image.Paletted, pixels [][3]float64, kernel []diffusionWeight, opts DitherOptions) {
+	width, height := out.Rect.Dx(), out.Rect.Dy()
+	for y := 0; y < height; y++ {
+		reverse := opts.Serpentine && y%2 == 1
+		for i := 0; i < width; i++ {
+			x, dir := i, 1
+			if reverse {
+				x, dir = width-1-i, -1
+			}
+
+			alpha := d.alphas[y*width+x]
+			if alpha == 0 {
+				// Invisible pixels have no error worth spreading
+				out.SetColorIndex(x, y, d.palette.index(0, alpha))
+				continue
+			}
+			current := pixels[y*width+x]
+			index := d.nearest(current)
+			out.SetColorIndex(x, y, d.palette.index(index, alpha))
+
+			chosen := d.entries[index]
+			var diff [3]float64
+			for c := range diff {
+				diff[c] = (current[c] - chosen[c]) * opts.Strength
+			}
+			for _, w := range kernel {
+				nx, ny := x+w.dx*dir, y+w.dy
+				if nx < 0 || nx >= width || ny >= height {
+					continue
+				}
+				for c := range diff {
+					pixels[ny*width+nx][c] += diff[c] * w.weight
+				}
+			}
+		}
+	}
+}
+
+// ordered offsets each pixel by its threshold in the Bayer matrix before picking the nearest color
+func (d *ditherer) ordered(out *image.Paletted, pixels [][3]float64, matrix [][]float64, numColors int, strength float64) {
+	// The offsets span roughly the distance between palette colors on each channel
+	spread := strength / math.Cbrt(float64(numColors))
+	width, height := out.Rect.Dx(), out.Rect.Dy()
+	size := len(matrix)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			offset := matrix[y%size][x%size] * spread
+			v := pixels[y*width+x]
+			if d.space == SpaceLab {
+				v[0] += offset
+			} else {
+				v[0], v[1], v[2] = v[0]+offset, v[1]+offset, v[2]+offset
+			}
+			out.SetColorIndex(x, y, d.palette.index(d.nearestInSpace(v), d.alphas[y*width+x]))
+		}
+	}
+}
+
+// bayerMatrix returns the size x size Bayer threshold matrix, with thresholds centered on zero in (-0.5, 0.5)
+func bayerMatrix(size int) [][]float64 {
+	indices := [][]int{{0}}
+	for n := 1; n < size; n *= 2 {
+		next := make([][]int, 2*n)
+		for y := range next {
+			next[y] = make([]int, 2*n)
+			for x := range next[y] {
+				quadrant := [2][2]int{{0, 2}, {3, 1}}[y/n][x/n]
+				next[y][x] = 4*indices[y%n][x%n] + quadrant
+			}
+		}
+		indices = next
+	}
+
+	cells := float64(size * size)
+	matrix := make([][]float64, size)
+	for y := range matrix {
+		matrix[y] = make([]float64, size)
+		for x := range matrix[y] {
+			matrix[y][x] = (float64(indices[y][x])+0.5)/cells - 0.5
+		}
+	}
+	return matrix
+}
@@ -0,0 +1,132 @@
+package palette
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDiffusionKernels(t *testing.T) {
+	// Atkinson deliberately drops a quarter of the error; the others spread all of it
+	want := map[string]float64{DitherFloydSteinberg: 1, DitherAtkinson: 0.75, DitherSierra: 1}
+	for method, kernel := range diffusionKernels {
+		sum := 0.0
+		for _, w := range kernel {
+			sum += w.weight
+			if w.dy < 0 || (w.dy == 0 && w.dx <= 0) {
+				t.Errorf("%s spreads error to %d,%d, which was already visited", method, w.dx, w.dy)
+			}
+		}
+		if math.Abs(sum-want[method]) > 1e-12 {
+			t.Errorf("%s weights sum to %g, want %g", method, sum, want[method])
+		}
+	}
+}
+
+func TestBayerMatrix(t *testing.T) {
+	want2 := [][]float64{{-0.375, 0.125}, {0.375, -0.125}}
+	if got := bayerMatrix(2); !reflect.DeepEqual(got, want2) {
+		t.Errorf("bayerMatrix(2) = %v, want %v", got, want2)
+	}
+
+	for _, size := range []int{2, 4, 8} {
+		matrix := bayerMatrix(size)
+		seen := map[float64]bool{}
+		sum := 0.0
+		for _, row := range matrix {
+			for _, v := range row {
+				seen[v] = true
+				sum += v
+			}
+		}
+		if len(seen) != size*size {
+			t.Errorf("bayerMatrix(%d) has %d distinct thresholds, want %d", size, len(seen), size*size)
+		}
+		if math.Abs(sum) > 1e-9 {
+			t.Errorf("bayerMatrix(%d) thresholds sum to %g, want them centered on 0", size, sum)
+		}
+	}
+}
+
+func TestDitherGray(t *testing.T) {
+	// Middle gray in linear light, dithered to black and white, should come out half white
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	gray := uint8(math.Round(255 * linearToSRGB(0.5)))
+	for i := range img.Pix {
+		img.Pix[i] = gray
+		if i%4 == 3 {
+			img.Pix[i] = 255
+		}
+	}
+	colors := map[string]int{"#000000": 1, "#ffffff": 1}
+
+	for _, method := range DitherMethods {
+		t.Run(method, func(t *testing.T) {
+			out, err := Dither(img, colors, DitherOptions{Method: method, Strength: 1, Space: SpaceLinear})
+			if err != nil {
+				t.Fatal(err)
+			}
+			white := 0
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					if out.At(x, y) == (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+						white++
+					}
+				}
+			}
+			share := float64(white) / (32 * 32)
+			switch method {
+			case DitherNone:
+				// Plain remapping picks the perceptually nearest color for every pixel alike
+				if share != 0 && share != 1 {
+					t.Errorf("share of white = %g, want all or nothing", share)
+				}
+			case DitherAtkinson:
+				// Dropping part of the error pulls the result towards the nearest color
+				if share < 0.3 || share > 0.7 {
+					t.Errorf("share of white = %g, want roughly half", share)
+				}
+			default:
+				if math.Abs(share-0.5) > 0.05 {
+					t.Errorf("share of white = %g, want 0.5", share)
+				}
+			}
+		})
+	}
+}
+
+// linearToSRGB applies the sRGB transfer function
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return 12.92 * v
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func TestDitherStrengthZero(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+		if i%4 == 3 {
+			img.Pix[i] = 255
+		}
+	}
+	colors := map[string]int{"#000000": 3, "#ff0000": 2, "#00ff00": 1}
+
+	want, err := Remap(img, colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without strength, the error diffusion methods work in Lab and reduce to plain remapping
+	for _, method := range []string{DitherFloydSteinberg, DitherAtkinson, DitherSierra} {
+		got, err := Dither(img, colors, DitherOptions{Method: method, Space: SpaceLab})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Pix, want.Pix) {
+			t.Errorf("%s at strength 0 differs from Remap", method)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package palette
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Color harmony schemes
+const (
+	HarmonyComplementary      = "complementary"       // The base and the opposite hue
+	HarmonySplitComplementary = "split-complementary" // The base and the two hues next to its complement
+	HarmonyAnalogous          = "analogous"           // The base between its two neighbouring hues
+	HarmonyTriadic            = "triadic"             // Three hues evenly spaced
+	HarmonyTetradic           = "tetradic"            // Four hues evenly spaced
+)
+
+// HarmonySchemes lists the supported harmony schemes
+var HarmonySchemes = []string{HarmonyComplementary, HarmonySplitComplementary, HarmonyAnalogous, HarmonyTriadic, HarmonyTetradic}
+
+// harmonyOffsets are the hue rotations of each scheme, in degrees
+var harmonyOffsets = map[string][]float64{
+	HarmonyComplementary:      {0, 180},
+	HarmonySplitComplementary: {0, 150, 210},
+	HarmonyAnalogous:          {-30, 0, 30},
+	HarmonyTriadic:            {0, 120, 240},
+	HarmonyTetradic:           {0, 90, 180, 270},
+}
+
+// Ramps derived from a base color
+const (
+	RampTints  = "tints"  // Toward white
+	RampShades = "shades" // Toward black
+	RampTones  = "tones"  // Toward the gray of the same lightness
+)
+
+// HarmonyRamps lists the supported ramps
+var HarmonyRamps = []string{RampTints, RampShades, RampTones}
+
+// Color spaces harmonies and ramps are computed in
+const (
+	SpaceOklch = "oklch"
+	SpaceHCL   = "hcl"
+)
+
+// HarmonySpaces lists the color spaces harmonies can be computed in
+var HarmonySpaces = []string{SpaceOklch, SpaceHCL}
+
+// Harmony returns the colors of a scheme built on base by rotating its hue, keeping lightness and chroma
+func Harmony(base colorful.Color, scheme, space string) ([]colorful.Color, error) {
+	offsets, ok := harmonyOffsets[scheme]
+	if !ok {
+		return nil, fmt.Errorf("invalid harmony scheme: %s. Supported schemes: %s", scheme, strings.Join(HarmonySchemes, ", "))
+	}
+	lch, err := newLCh(space)
+	if err != nil {
+		return nil, err
+	}
+
+	l, c, h := lch.from(base)
+	colors := make([]colorful.Color, len(offsets))
+	for i, offset := range offsets {
+		if offset == 0 {
+			colors[i] = base
+			continue
+		}
+		colors[i] = lch.to(l, c, math.Mod(h+offset+360, 360))
+	}
+	return colors, nil
+}
+
+// HarmonyRamp returns steps colors going from base toward white, black or gray, base first
+func HarmonyRamp(base colorful.Color, ramp, space string, steps int) ([]colorful.Color, error) {
+	if steps < 2 {
+		return nil, fmt.Errorf("invalid number of ramp steps: %d. It must be at least 2", steps)
+	}
+	lch, err := newLCh(space)
+	if err != nil {
+		return nil, err
+	}
+
+	l, c, h := lch.from(base)
+	colors := make([]colorful.Color, steps)
+	for i := range colors {
+		// The last step stops short of pure white, black or gray so it still carries the hue
+		t := float64(i) / float64(steps) * 0.9
+		switch ramp {
+		case RampTints:
+			colors[i] = lch.to(l+(1-l)*t, c*(1-t), h)
+		case RampShades:
+			colors[i] = lch.to(l*(1-t), c*(1-t), h)
+		case RampTones:
+			colors[i] = lch.to(l, c*(1-t), h)
+		default:
+			return nil, fmt.Errorf("invalid ramp: %s. Supported ramps: %s", ramp, strings.Join(HarmonyRamps, ", "))
+		}
+	}
+	colors[0] = base
+	return colors, nil
+}
+
+// lchSpace converts between colors and a cylindrical lightness, chroma, hue space
+type lchSpace struct {
+	from func(c colorful.Color) (l, chroma, h float64)
+	to   func(l, c, h float64) colorful.Color
+}
+
+func newLCh(space string) (lchSpace, error) {
+	switch space {
+	case "", SpaceOklch:
+		return lchSpace{from: Oklch, to: FromOklch}, nil
+	case SpaceHCL:
+		return lchSpace{
+			from: func(c colorful.Color) (float64, float64, float64) {
+				h, chroma, l := c.Hcl()
+				return l, chroma, h
+			},
+			to: func(l, c, h float64) colorful.Color {
+				l = clamp(l, 0, 1)
+				// Clamping the channels would shift the hue, so the chroma is lowered as in FromOklch
+				return reduceChroma(c, func(c float64) colorful.Color { return colorful.Hcl(h, c, l) })
+			},
+		}, nil
+	default:
+		return lchSpace{}, fmt.Errorf("invalid harmony space: %s. Supported spaces: %s", space, strings.Join(HarmonySpaces, ", "))
+	}
+}
@@ -0,0 +1,83 @@
+package palette
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+func TestHarmonyKeepsHueOutOfGamut(t *testing.T) {
+	// Saturated blue's complement at the same lightness and chroma is far outside sRGB
+	base := mustHex(t, "#0000ff")
+	for _, space := range HarmonySpaces {
+		colors, err := Harmony(base, HarmonyTriadic, space)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lch, _ := newLCh(space)
+		l, _, h := lch.from(base)
+		for i, offset := range []float64{0, 120, 240} {
+			c := colors[i]
+			if !c.IsValid() {
+				t.Errorf("%s: color %d = %v, outside sRGB", space, i, c)
+			}
+			gotL, _, gotH := lch.from(c)
+			want := math.Mod(h+offset, 360)
+			if d := HueDistance(gotH, want); d > 1 {
+				t.Errorf("%s: color %d (%s) hue = %.1f, want %.1f", space, i, c.Hex(), gotH, want)
+			}
+			if abs(gotL-l) > 0.01 {
+				t.Errorf("%s: color %d (%s) lightness = %.3f, want %.3f", space, i, c.Hex(), gotL, l)
+			}
+		}
+	}
+}
+
+func TestHarmonyRamp(t *testing.T) {
+	base := mustHex(t, "#3366cc")
+	for _, space := range HarmonySpaces {
+		lch, _ := newLCh(space)
+		_, _, h := lch.from(base)
+		for _, ramp := range HarmonyRamps {
+			colors, err := HarmonyRamp(base, ramp, space, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(colors) != 5 || colors[0] != base {
+				t.Fatalf("%s/%s: got %d colors starting with %s, want 5 starting with the base", space, ramp, len(colors), colors[0].Hex())
+			}
+			previousL, previousC, _ := lch.from(base)
+			for i, c := range colors[1:] {
+				l, chroma, gotH := lch.from(c)
+				if d := HueDistance(gotH, h); d > 2 {
+					t.Errorf("%s/%s: step %d (%s) hue = %.1f, want %.1f", space, ramp, i+1, c.Hex(), gotH, h)
+				}
+				if chroma > previousC+1e-9 {
+					t.Errorf("%s/%s: step %d (%s) gains chroma", space, ramp, i+1, c.Hex())
+				}
+				switch {
+				case ramp == RampTints && l <= previousL, ramp == RampShades && l >= previousL:
+					t.Errorf("%s/%s: step %d (%s) lightness %.3f doesn't move away from %.3f", space, ramp, i+1, c.Hex(), l, previousL)
+				}
+				previousL, previousC = l, chroma
+			}
+		}
+	}
+}
+
+func TestHarmonyErrors(t *testing.T) {
+	base := colorful.Color{R: 0.5, G: 0.2, B: 0.1}
+	if _, err := Harmony(base, "square", SpaceOklch); err == nil {
+		t.Error("Harmony accepted an unknown scheme")
+	}
+	if _, err := Harmony(base, HarmonyTriadic, "hsl"); err == nil {
+		t.Error("Harmony accepted an unknown space")
+	}
+	if _, err := HarmonyRamp(base, RampTints, SpaceOklch, 1); err == nil {
+		t.Error("HarmonyRamp accepted a single step")
+	}
+	if _, err := HarmonyRamp(base, "glazes", SpaceOklch, 3); err == nil {
+		t.Error("HarmonyRamp accepted an unknown ramp")
+	}
+}
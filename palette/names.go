@@ -0,0 +1,109 @@
+package palette
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Color name dictionaries
+const (
+	DictionaryCSS     = "css"      // CSS named colors
+	DictionaryXKCD    = "xkcd"     // Common names from the XKCD color survey
+	DictionaryPantone = "pantone"  // Approximations of well-known Pantone colors
+	DictionaryISCCNBS = "iscc-nbs" // ISCC-NBS centroid color names
+)
+
+// Dictionaries lists the supported color name dictionaries
+var Dictionaries = []string{DictionaryCSS, DictionaryXKCD, DictionaryPantone, DictionaryISCCNBS}
+
+// Color difference measures used to find the nearest name
+const (
+	MatchLab       = "lab"       // Euclidean distance in Lab (CIE76)
+	MatchCIEDE2000 = "ciede2000" // CIEDE2000 color difference
+)
+
+// MatchMethods lists the supported color difference measures
+var MatchMethods = []string{MatchLab, MatchCIEDE2000}
+
+// ColorName is the dictionary entry nearest to a color
+type ColorName struct {
+	Name     string
+	Hex      string  // Color of the dictionary entry
+	Distance float64 // Difference between the color and the entry, in Lab units (0-100 lightness scale)
+}
+
+// ColorNamer finds the nearest name for colors in one dictionary
+type ColorNamer struct {
+	entries []namedColor
+	colors  []colorful.Color
+	match   string
+}
+
+// NewColorNamer returns a namer for the dictionary, matching with the given color difference
+func NewColorNamer(dictionary, match string) (*ColorNamer, error) {
+	if match == "" {
+		match = MatchCIEDE2000
+	}
+	if match != MatchLab && match != MatchCIEDE2000 {
+		return nil, fmt.Errorf("invalid name matching: %s. Supported methods: %s", match, strings.Join(MatchMethods, ", "))
+	}
+
+	var entries []namedColor
+	switch dictionary {
+	case DictionaryCSS:
+		entries = cssColors
+	case DictionaryXKCD:
+		entries = xkcdColors
+	case DictionaryPantone:
+		entries = pantoneLikeColors
+	case DictionaryISCCNBS:
+		entries = isccNBSColors
+	default:
+		return nil, fmt.Errorf("invalid color dictionary: %s. Supported dictionaries: %s", dictionary, strings.Join(Dictionaries, ", "))
+	}
+
+	namer := &ColorNamer{entries: entries, colors: make([]colorful.Color, len(entries)), match: match}
+	for i, entry := range entries {
+		c, err := colorful.Hex(entry.hex)
+		if err != nil {
+			return nil, err
+		}
+		namer.colors[i] = c
+	}
+	return namer, nil
+}
+
+// Name returns the dictionary entry nearest to the color
+func (n *ColorNamer) Name(hex string) (ColorName, error) {
+	c, err := colorful.Hex(hex)
+	if err != nil {
+		return ColorName{}, err
+	}
+
+	best, bestDistance := 0, -1.0
+	for i, entry := range n.colors {
+		var distance float64
+		if n.match == MatchLab {
+			distance = c.DistanceLab(entry)
+		} else {
+			distance = c.DistanceCIEDE2000(entry)
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return ColorName{Name: n.entries[best].name, Hex: n.entries[best].hex, Distance: bestDistance * 100}, nil
+}
+
+// Names maps every color of a palette to its nearest name
+func (n *ColorNamer) Names(colors map[string]int) map[string]string {
+	names := make(map[string]string, len(colors))
+	for hex := range colors {
+		if name, err := n.Name(hex); err == nil {
+			names[hex] = name.Name
+		}
+	}
+	return names
+}
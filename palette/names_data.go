@@ -0,0 +1,187 @@
+package palette
+
+// namedColor is a dictionary entry
+type namedColor struct {
+	name string
+	hex  string
+}
+
+// cssColors are the CSS Color Module Level 4 named colors. Aliases (aqua, fuchsia and the grey
+// spellings) are left out so every color has one name.
+var cssColors = []namedColor{
+	{"aliceblue", "#f0f8ff"}, {"antiquewhite", "#faebd7"}, {"aquamarine", "#7fffd4"}, {"azure", "#f0ffff"},
+	{"beige", "#f5f5dc"}, {"bisque", "#ffe4c4"}, {"black", "#000000"}, {"blanchedalmond", "#ffebcd"},
+	{"blue", "#0000ff"}, {"blueviolet", "#8a2be2"}, {"brown", "#a52a2a"}, {"burlywood", "#deb887"},
+	{"cadetblue", "#5f9ea0"}, {"chartreuse", "#7fff00"}, {"chocolate", "#d2691e"}, {"coral", "#ff7f50"},
+	{"cornflowerblue", "#6495ed"}, {"cornsilk", "#fff8dc"}, {"crimson", "#dc143c"}, {"cyan", "#00ffff"},
+	{"darkblue", "#00008b"}, {"darkcyan", "#008b8b"}, {"darkgoldenrod", "#b8860b"}, {"darkgray", "#a9a9a9"},
+	{"darkgreen", "#006400"}, {"darkkhaki", "#bdb76b"}, {"darkmagenta", "#8b008b"}, {"darkolivegreen", "#556b2f"},
+	{"darkorange", "#ff8c00"}, {"darkorchid", "#9932cc"}, {"darkred", "#8b0000"}, {"darksalmon", "#e9967a"},
+	{"darkseagreen", "#8fbc8f"}, {"darkslateblue", "#483d8b"}, {"darkslategray", "#2f4f4f"}, {"darkturquoise", "#00ced1"},
+	{"darkviolet", "#9400d3"}, {"deeppink", "#ff1493"}, {"deepskyblue", "#00bfff"}, {"dimgray", "#696969"},
+	{"dodgerblue", "#1e90ff"}, {"firebrick", "#b22222"}, {"floralwhite", "#fffaf0"}, {"forestgreen", "#228b22"},
+	{"gainsboro", "#dcdcdc"}, {"ghostwhite", "#f8f8ff"}, {"gold", "#ffd700"}, {"goldenrod", "#daa520"},
+	{"gray", "#808080"}, {"green", "#008000"}, {"greenyellow", "#adff2f"}, {"honeydew", "#f0fff0"},
+	{"hotpink", "#ff69b4"}, {"indianred", "#cd5c5c"}, {"indigo", "#4b0082"}, {"ivory", "#fffff0"},
+	{"khaki", "#f0e68c"}, {"lavender", "#e6e6fa"}, {"lavenderblush", "#fff0f5"}, {"lawngreen", "#7cfc00"},
+	{"lemonchiffon", "#fffacd"}, {"lightblue", "#add8e6"}, {"lightcoral", "#f08080"}, {"lightcyan", "#e0ffff"},
+	{"lightgoldenrodyellow", "#fafad2"}, {"lightgray", "#d3d3d3"}, {"lightgreen", "#90ee90"}, {"lightpink", "#ffb6c1"},
+	{"lightsalmon", "#ffa07a"}, {"lightseagreen", "#20b2aa"}, {"lightskyblue", "#87cefa"}, {"lightslategray", "#778899"},
+	{"lightsteelblue", "#b0c4de"}, {"lightyellow", "#ffffe0"}, {"lime", "#00ff00"}, {"limegreen", "#32cd32"},
+	{"linen", "#faf0e6"}, {"magenta", "#ff00ff"}, {"maroon", "#800000"}, {"mediumaquamarine", "#66cdaa"},
+	{"mediumblue", "#0000cd"}, {"mediumorchid", "#ba55d3"}, {"mediumpurple", "#9370db"}, {"mediumseagreen", "#3cb371"},
+	{"mediumslateblue", "#7b68ee"}, {"mediumspringgreen", "#00fa9a"}, {"mediumturquoise", "#48d1cc"}, {"mediumvioletred", "#c71585"},
+	{"midnightblue", "#191970"}, {"mintcream", "#f5fffa"}, {"mistyrose", "#ffe4e1"}, {"moccasin", "#ffe4b5"},
+	{"navajowhite", "#ffdead"}, {"navy", "#000080"}, {"oldlace", "#fdf5e6"}, {"olive", "#808000"},
+	{"olivedrab", "#6b8e23"}, {"orange", "#ffa500"}, {"orangered", "#ff4500"}, {"orchid", "#da70d6"},
+	{"palegoldenrod", "#eee8aa"}, {"palegreen", "#98fb98"}, {"paleturquoise", "#afeeee"}, {"palevioletred", "#db7093"},
+	{"papayawhip", "#ffefd5"}, {"peachpuff", "#ffdab9"}, {"peru", "#cd853f"}, {"pink", "#ffc0cb"},
+	{"plum", "#dda0dd"}, {"powderblue", "#b0e0e6"}, {"purple", "#800080"}, {"rebeccapurple", "#663399"},
+	{"red", "#ff0000"}, {"rosybrown", "#bc8f8f"}, {"royalblue", "#4169e1"}, {"saddlebrown", "#8b4513"},
+	{"salmon", "#fa8072"}, {"sandybrown", "#f4a460"}, {"seagreen", "#2e8b57"}, {"seashell", "#fff5ee"},
+	{"sienna", "#a0522d"}, {"silver", "#c0c0c0"}, {"skyblue", "#87ceeb"}, {"slateblue", "#6a5acd"},
+	{"slategray", "#708090"}, {"snow", "#fffafa"}, {"springgreen", "#00ff7f"}, {"steelblue", "#4682b4"},
+	{"tan", "#d2b48c"}, {"teal", "#008080"}, {"thistle", "#d8bfd8"}, {"tomato", "#ff6347"},
+	{"turquoise", "#40e0d0"}, {"violet", "#ee82ee"}, {"wheat", "#f5deb3"}, {"white", "#ffffff"},
+	{"whitesmoke", "#f5f5f5"}, {"yellow", "#ffff00"}, {"yellowgreen", "#9acd32"},
+}
+
+// xkcdColors is a subset of the most common names from the XKCD color survey
+var xkcdColors = []namedColor{
+	{"purple", "#7e1e9c"}, {"green", "#15b01a"}, {"blue", "#0343df"}, {"pink", "#ff81c0"},
+	{"brown", "#653700"}, {"red", "#e50000"}, {"light blue", "#95d0fc"}, {"teal", "#029386"},
+	{"orange", "#f97306"}, {"light green", "#96f97b"}, {"magenta", "#c20078"}, {"yellow", "#ffff14"},
+	{"sky blue", "#75bbfd"}, {"grey", "#929591"}, {"lime green", "#89fe05"}, {"light purple", "#bf77f6"},
+	{"violet", "#9a0eea"}, {"dark green", "#033500"}, {"turquoise", "#06c2ac"}, {"lavender", "#c79fef"},
+	{"dark blue", "#00035b"}, {"tan", "#d1b26f"}, {"cyan", "#00ffff"}, {"aqua", "#13eac9"},
+	{"forest green", "#06470c"}, {"mauve", "#ae7181"}, {"dark purple", "#35063e"}, {"bright green", "#01ff07"},
+	{"maroon", "#650021"}, {"olive", "#6e750e"}, {"salmon", "#ff796c"}, {"beige", "#e6daa6"},
+	{"royal blue", "#0504aa"}, {"navy blue", "#001146"}, {"lilac", "#cea2fd"}, {"black", "#000000"},
+	{"hot pink", "#ff028d"}, {"light brown", "#ad8150"}, {"pale green", "#c7fdb5"}, {"peach", "#ffb07c"},
+	{"olive green", "#677a04"}, {"dark pink", "#cb416b"}, {"periwinkle", "#8e82fe"}, {"sea green", "#53fca1"},
+	{"lime", "#aaff32"}, {"indigo", "#380282"}, {"mustard", "#ceb301"}, {"light pink", "#ffd1df"},
+	{"rose", "#cf6275"}, {"bright blue", "#0165fc"}, {"neon green", "#0cff0c"}, {"burnt orange", "#c04e01"},
+	{"aquamarine", "#04d8b2"}, {"navy", "#01153e"}, {"grass green", "#3f9b0b"}, {"pale blue", "#d0fefe"},
+	{"dark red", "#840000"}, {"bright purple", "#be03fd"}, {"yellow green", "#c0fb2d"}, {"baby blue", "#a2cffe"},
+	{"gold", "#dbb40c"}, {"mint green", "#8fff9f"}, {"plum", "#580f41"}, {"royal purple", "#4b006e"},
+	{"brick red", "#8f1402"}, {"dark teal", "#014d4e"}, {"burgundy", "#610023"}, {"khaki", "#aaa662"},
+	{"blue green", "#137e6d"}, {"seafoam green", "#7af9ab"}, {"pea green", "#8eab12"}, {"taupe", "#b9a281"},
+	{"dark brown", "#341c02"}, {"deep purple", "#36013f"}, {"chartreuse", "#c1f80a"}, {"bright pink", "#fe01b1"},
+	{"goldenrod", "#fac205"}, {"cerulean", "#0485d1"}, {"light grey", "#d8dcd6"}, {"dark grey", "#363737"},
+	{"slate", "#516572"}, {"sand", "#e2ca76"}, {"cream", "#ffffc2"}, {"ochre", "#bf9005"},
+	{"coral", "#fc5a50"}, {"terracotta", "#ca6641"}, {"white", "#ffffff"}, {"charcoal", "#343837"},
+}
+
+// pantoneLikeColors approximates a selection of well-known Pantone colors in sRGB. They are
+// screen approximations for orientation only, not color-managed Pantone references.
+var pantoneLikeColors = []namedColor{
+	{"Viva Magenta (18-1750)", "#bb2649"}, {"Very Peri (17-3938)", "#6667ab"}, {"Illuminating (13-0647)", "#f5df4d"},
+	{"Ultimate Gray (17-5104)", "#939597"}, {"Classic Blue (19-4052)", "#0f4c81"}, {"Living Coral (16-1546)", "#ff6f61"},
+	{"Ultra Violet (18-3838)", "#5f4b8b"}, {"Greenery (15-0343)", "#88b04b"}, {"Rose Quartz (13-1520)", "#f7cac9"},
+	{"Serenity (15-3919)", "#92a8d1"}, {"Marsala (18-1438)", "#955251"}, {"Radiant Orchid (18-3224)", "#b163a3"},
+	{"Emerald (17-5641)", "#009473"}, {"Tangerine Tango (17-1463)", "#dd4124"}, {"Honeysuckle (18-2120)", "#d94f70"},
+	{"Turquoise (15-5519)", "#45b5aa"}, {"Chili Pepper (19-1557)", "#9b1b30"}, {"Mimosa (14-0848)", "#f0c05a"},
+	{"Blue Iris (18-3943)", "#5a5b9f"}, {"Sand Dollar (13-1106)", "#decdbe"}, {"Fuchsia Rose (17-2031)", "#c74375"},
+	{"Cerulean (15-4020)", "#9bb7d4"}, {"Peach Fuzz (13-1023)", "#ffbe98"}, {"Mocha Mousse (17-1230)", "#a47864"},
+	{"True Red (19-1664)", "#bf1932"}, {"Aqua Sky (14-4811)", "#7bc4c4"}, {"Tigerlily (17-1456)", "#e2583e"},
+	{"Cerise (19-1955)", "#a4344a"}, {"Black (19-0303)", "#2d2c2f"}, {"Bright White (11-0601)", "#f4f5f0"},
+}
+
+// isccNBSColors are the 267 ISCC-NBS centroid colors, numbered 1 to 267 in this order, as sRGB.
+// They are the centroids of the named blocks of Munsell color space published in NBS Special
+// Publication 440 (Kelly and Judd, 1976).
+var isccNBSColors = []namedColor{
+	{"vivid pink", "#ffb5ba"}, {"strong pink", "#ea9399"}, {"deep pink", "#e4717a"}, {"light pink", "#f9ccca"},
+	{"moderate pink", "#dea5a4"}, {"dark pink", "#c08081"}, {"pale pink", "#ead8d7"}, {"grayish pink", "#c4aead"},
+	{"pinkish white", "#eae3e1"}, {"pinkish gray", "#c1b6b3"}, {"vivid red", "#be0032"}, {"strong red", "#bc3f4a"},
+	{"deep red", "#841b2d"}, {"very deep red", "#5c0923"}, {"moderate red", "#ab4e52"}, {"dark red", "#722f37"},
+	{"very dark red", "#3f1728"}, {"light grayish red", "#ad8884"}, {"grayish red", "#905d5d"},
+	{"dark grayish red", "#543d3f"}, {"blackish red", "#2e1d21"}, {"reddish gray", "#8f817f"},
+	{"dark reddish gray", "#5c504f"}, {"reddish black", "#282022"}, {"vivid yellowish pink", "#ffb7a5"},
+	{"strong yellowish pink", "#f99379"}, {"deep yellowish pink", "#e66761"}, {"light yellowish pink", "#f4c2c2"},
+	{"moderate yellowish pink", "#d9a6a9"}, {"dark yellowish pink", "#c48379"}, {"pale yellowish pink", "#ecd5c5"},
+	{"grayish yellowish pink", "#c7ada3"}, {"brownish pink", "#c2ac99"}, {"vivid reddish orange", "#e25822"},
+	{"strong reddish orange", "#d9603b"}, {"deep reddish orange", "#aa381e"},
+	{"moderate reddish orange", "#cb6d51"}, {"dark reddish orange", "#9e4732"},
+	{"grayish reddish orange", "#b4745e"}, {"strong reddish brown", "#882d17"}, {"deep reddish brown", "#56070c"},
+	{"light reddish brown", "#a87c6d"}, {"moderate reddish brown", "#79443b"}, {"dark reddish brown", "#3e1d1e"},
+	{"light grayish reddish brown", "#977f73"}, {"grayish reddish brown", "#674c47"},
+	{"dark grayish reddish brown", "#43302e"}, {"vivid orange", "#f38400"}, {"brilliant orange", "#fd943f"},
+	{"strong orange", "#ed872d"}, {"deep orange", "#be6516"}, {"light orange", "#fab57f"},
+	{"moderate orange", "#d99058"}, {"brownish orange", "#ae6938"}, {"strong brown", "#80461b"},
+	{"deep brown", "#593319"}, {"light brown", "#a67b5b"}, {"moderate brown", "#6f4e37"}, {"dark brown", "#422518"},
+	{"light grayish brown", "#958070"}, {"grayish brown", "#635147"}, {"dark grayish brown", "#3e322c"},
+	{"light brownish gray", "#8e8279"}, {"brownish gray", "#5b504f"}, {"brownish black", "#28201c"},
+	{"vivid orange yellow", "#f6a600"}, {"brilliant orange yellow", "#ffc14f"}, {"strong orange yellow", "#eaa221"},
+	{"deep orange yellow", "#c98500"}, {"light orange yellow", "#fbc97f"}, {"moderate orange yellow", "#e3a857"},
+	{"dark orange yellow", "#be8a3d"}, {"pale orange yellow", "#fad6a5"}, {"strong yellowish brown", "#996515"},
+	{"deep yellowish brown", "#654522"}, {"light yellowish brown", "#c19a6b"},
+	{"moderate yellowish brown", "#826644"}, {"dark yellowish brown", "#4b3621"},
+	{"light grayish yellowish brown", "#ae9b82"}, {"grayish yellowish brown", "#7e6d5a"},
+	{"dark grayish yellowish brown", "#483c32"}, {"vivid yellow", "#f3c300"}, {"brilliant yellow", "#fada5e"},
+	{"strong yellow", "#d4af37"}, {"deep yellow", "#af8d13"}, {"light yellow", "#f8de7e"},
+	{"moderate yellow", "#c9ae5d"}, {"dark yellow", "#ab9144"}, {"pale yellow", "#f3e5ab"},
+	{"grayish yellow", "#c2b280"}, {"dark grayish yellow", "#a18f60"}, {"yellowish white", "#f0ead6"},
+	{"yellowish gray", "#bfb8a5"}, {"light olive brown", "#967117"}, {"moderate olive brown", "#6c541e"},
+	{"dark olive brown", "#3b3121"}, {"vivid greenish yellow", "#dcd300"}, {"brilliant greenish yellow", "#e9e450"},
+	{"strong greenish yellow", "#beb72e"}, {"deep greenish yellow", "#9b9400"},
+	{"light greenish yellow", "#eae679"}, {"moderate greenish yellow", "#b9b459"},
+	{"dark greenish yellow", "#98943e"}, {"pale greenish yellow", "#ebe8a4"},
+	{"grayish greenish yellow", "#b9b57d"}, {"light olive", "#867e36"}, {"moderate olive", "#665d1e"},
+	{"dark olive", "#403d21"}, {"light grayish olive", "#8c8767"}, {"grayish olive", "#5b5842"},
+	{"dark grayish olive", "#363527"}, {"light olive gray", "#8a8776"}, {"olive gray", "#57554c"},
+	{"olive black", "#25241d"}, {"vivid yellow green", "#8db600"}, {"brilliant yellow green", "#bdda57"},
+	{"strong yellow green", "#7e9f2e"}, {"deep yellow green", "#467129"}, {"light yellow green", "#c9dc89"},
+	{"moderate yellow green", "#8a9a5b"}, {"pale yellow green", "#dadfb7"}, {"grayish yellow green", "#8f9779"},
+	{"strong olive green", "#404f00"}, {"deep olive green", "#232f00"}, {"moderate olive green", "#4a5d23"},
+	{"dark olive green", "#2b3d26"}, {"grayish olive green", "#515744"}, {"dark grayish olive green", "#31362b"},
+	{"vivid yellowish green", "#27a64c"}, {"brilliant yellowish green", "#83d37d"},
+	{"strong yellowish green", "#44944a"}, {"deep yellowish green", "#00622d"},
+	{"very deep yellowish green", "#003118"}, {"very light yellowish green", "#b6e5af"},
+	{"light yellowish green", "#93c592"}, {"moderate yellowish green", "#679267"},
+	{"dark yellowish green", "#355e3b"}, {"very dark yellowish green", "#173620"}, {"vivid green", "#008856"},
+	{"brilliant green", "#3eb489"}, {"strong green", "#007959"}, {"deep green", "#00543d"},
+	{"very light green", "#8ed1b2"}, {"light green", "#6aab8e"}, {"moderate green", "#3b7861"},
+	{"dark green", "#1b4d3e"}, {"very dark green", "#1c352d"}, {"very pale green", "#c7e6d7"},
+	{"pale green", "#8da399"}, {"grayish green", "#5e716a"}, {"dark grayish green", "#3a4b47"},
+	{"blackish green", "#1a2421"}, {"greenish white", "#dfede8"}, {"light greenish gray", "#b2beb5"},
+	{"greenish gray", "#7d8984"}, {"dark greenish gray", "#4e5755"}, {"greenish black", "#1e2321"},
+	{"vivid bluish green", "#008882"}, {"brilliant bluish green", "#00a693"}, {"strong bluish green", "#007a74"},
+	{"deep bluish green", "#00443f"}, {"very light bluish green", "#96ded1"}, {"light bluish green", "#66ada4"},
+	{"moderate bluish green", "#317873"}, {"dark bluish green", "#004b49"}, {"very dark bluish green", "#002a29"},
+	{"vivid greenish blue", "#0085a1"}, {"brilliant greenish blue", "#239eba"}, {"strong greenish blue", "#007791"},
+	{"deep greenish blue", "#2e8495"}, {"very light greenish blue", "#9cd1dc"}, {"light greenish blue", "#66aabc"},
+	{"moderate greenish blue", "#367588"}, {"dark greenish blue", "#004958"},
+	{"very dark greenish blue", "#002e3b"}, {"vivid blue", "#00a1c2"}, {"brilliant blue", "#4997d0"},
+	{"strong blue", "#0067a5"}, {"deep blue", "#00416a"}, {"very light blue", "#a1caf1"}, {"light blue", "#70a3cc"},
+	{"moderate blue", "#436b95"}, {"dark blue", "#00304e"}, {"very pale blue", "#bcd4e6"}, {"pale blue", "#91a3b0"},
+	{"grayish blue", "#536878"}, {"dark grayish blue", "#36454f"}, {"blackish blue", "#202830"},
+	{"bluish white", "#e9e9ed"}, {"light bluish gray", "#b4bcc0"}, {"bluish gray", "#81878b"},
+	{"dark bluish gray", "#51585e"}, {"bluish black", "#202428"}, {"vivid purplish blue", "#30267a"},
+	{"brilliant purplish blue", "#6c79b8"}, {"strong purplish blue", "#545aa7"}, {"deep purplish blue", "#272458"},
+	{"very light purplish blue", "#b3bce2"}, {"light purplish blue", "#8791bf"},
+	{"moderate purplish blue", "#4e5180"}, {"dark purplish blue", "#252440"},
+	{"very pale purplish blue", "#c0c8e1"}, {"pale purplish blue", "#8c92ac"}, {"grayish purplish blue", "#4c516d"},
+	{"vivid violet", "#9065ca"}, {"brilliant violet", "#7e73b8"}, {"strong violet", "#604e97"},
+	{"deep violet", "#32174d"}, {"very light violet", "#dcd0ff"}, {"light violet", "#8c82b6"},
+	{"moderate violet", "#604e81"}, {"dark violet", "#2f2140"}, {"very pale violet", "#c4c3dd"},
+	{"pale violet", "#9690ab"}, {"grayish violet", "#554c69"}, {"vivid purple", "#9a4eae"},
+	{"brilliant purple", "#d399e6"}, {"strong purple", "#875692"}, {"deep purple", "#602f6b"},
+	{"very deep purple", "#401a4c"}, {"very light purple", "#d5badb"}, {"light purple", "#b695c0"},
+	{"moderate purple", "#86608e"}, {"dark purple", "#563c5c"}, {"very dark purple", "#301934"},
+	{"very pale purple", "#d6cadd"}, {"pale purple", "#aa98a9"}, {"grayish purple", "#796878"},
+	{"dark grayish purple", "#50404d"}, {"blackish purple", "#291e29"}, {"purplish white", "#e8e3e5"},
+	{"light purplish gray", "#bfb9bd"}, {"purplish gray", "#8b8589"}, {"dark purplish gray", "#5d555b"},
+	{"purplish black", "#242124"}, {"vivid reddish purple", "#870074"}, {"strong reddish purple", "#9e4f88"},
+	{"deep reddish purple", "#702963"}, {"very deep reddish purple", "#54194e"},
+	{"light reddish purple", "#b784a7"}, {"moderate reddish purple", "#915c83"}, {"dark reddish purple", "#5d3954"},
+	{"very dark reddish purple", "#341731"}, {"pale reddish purple", "#aa8a9e"},
+	{"grayish reddish purple", "#836479"}, {"brilliant purplish pink", "#ffc8d6"},
+	{"strong purplish pink", "#e68fac"}, {"deep purplish pink", "#de6fa1"}, {"light purplish pink", "#efbbcc"},
+	{"moderate purplish pink", "#d597ae"}, {"dark purplish pink", "#c17e91"}, {"pale purplish pink", "#e8ccd7"},
+	{"grayish purplish pink", "#c3a6b1"}, {"vivid purplish red", "#ce4676"}, {"strong purplish red", "#b3446c"},
+	{"deep purplish red", "#78184a"}, {"very deep purplish red", "#54133b"}, {"moderate purplish red", "#a8516e"},
+	{"dark purplish red", "#673147"}, {"very dark purplish red", "#38152c"},
+	{"light grayish purplish red", "#af868e"}, {"grayish purplish red", "#915f6d"}, {"white", "#f2f3f4"},
+	{"light gray", "#b9b8b5"}, {"medium gray", "#848482"}, {"dark gray", "#555555"}, {"black", "#222222"},
+}
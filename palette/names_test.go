@@ -0,0 +1,113 @@
+package palette
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorNamerExactEntries(t *testing.T) {
+	dictionaries := map[string][]namedColor{
+		DictionaryCSS:     cssColors,
+		DictionaryXKCD:    xkcdColors,
+		DictionaryPantone: pantoneLikeColors,
+		DictionaryISCCNBS: isccNBSColors,
+	}
+	for _, dictionary := range Dictionaries {
+		entries, ok := dictionaries[dictionary]
+		if !ok {
+			t.Fatalf("dictionary %s isn't covered by the test", dictionary)
+		}
+		for _, match := range MatchMethods {
+			namer, err := NewColorNamer(dictionary, match)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Every entry of a dictionary names its own color, so none is shadowed by a duplicate
+			for _, entry := range entries {
+				name, err := namer.Name(entry.hex)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if name.Name != entry.name || name.Hex != entry.hex || name.Distance != 0 {
+					t.Errorf("%s/%s: Name(%s) = %+v, want %q", dictionary, match, entry.hex, name, entry.name)
+				}
+			}
+		}
+	}
+}
+
+func TestISCCNBSCentroids(t *testing.T) {
+	if len(isccNBSColors) != 267 {
+		t.Errorf("got %d ISCC-NBS centroids, want 267", len(isccNBSColors))
+	}
+	// A few centroids by their ISCC-NBS number
+	for number, want := range map[int]namedColor{
+		1:   {"vivid pink", "#ffb5ba"},
+		11:  {"vivid red", "#be0032"},
+		48:  {"vivid orange", "#f38400"},
+		82:  {"vivid yellow", "#f3c300"},
+		139: {"vivid green", "#008856"},
+		178: {"strong blue", "#0067a5"},
+		216: {"vivid purple", "#9a4eae"},
+		263: {"white", "#f2f3f4"},
+		267: {"black", "#222222"},
+	} {
+		if got := isccNBSColors[number-1]; got != want {
+			t.Errorf("centroid %d = %v, want %v", number, got, want)
+		}
+	}
+}
+
+func TestColorNamerNearest(t *testing.T) {
+	tests := []struct {
+		dictionary, match, hex, want string
+	}{
+		{DictionaryCSS, MatchCIEDE2000, "#fe0102", "red"},
+		{DictionaryCSS, MatchLab, "#010101", "black"},
+		{DictionaryCSS, MatchCIEDE2000, "#808081", "gray"},
+		{DictionaryISCCNBS, MatchCIEDE2000, "#bf0133", "vivid red"},
+		{DictionaryISCCNBS, MatchCIEDE2000, "#1a1a1a", "black"},
+		{DictionaryISCCNBS, MatchCIEDE2000, "#f8f8f8", "white"},
+		{DictionaryISCCNBS, MatchCIEDE2000, "#0066a6", "strong blue"},
+		{DictionaryISCCNBS, MatchLab, "#6f4e38", "moderate brown"},
+	}
+	for _, test := range tests {
+		namer, err := NewColorNamer(test.dictionary, test.match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, err := namer.Name(test.hex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name.Name != test.want {
+			t.Errorf("%s/%s: Name(%s) = %q (%s), want %q", test.dictionary, test.match, test.hex, name.Name, name.Hex, test.want)
+		}
+		if name.Distance <= 0 || name.Distance > 5 {
+			t.Errorf("%s/%s: Name(%s) distance = %g, want a small positive difference", test.dictionary, test.match, test.hex, name.Distance)
+		}
+	}
+}
+
+func TestColorNamerNames(t *testing.T) {
+	namer, err := NewColorNamer(DictionaryCSS, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := namer.Names(map[string]int{"#ff0000": 3, "#0000ff": 1, "not a color": 1})
+	if len(names) != 2 || names["#ff0000"] != "red" || names["#0000ff"] != "blue" {
+		t.Errorf("Names = %v, want red and blue", names)
+	}
+	if _, err := namer.Name("red"); err == nil {
+		t.Error("Name accepted an invalid hex color")
+	}
+}
+
+func TestNewColorNamerErrors(t *testing.T) {
+	if _, err := NewColorNamer("crayola", MatchLab); err == nil || !strings.Contains(err.Error(), "invalid color dictionary") {
+		t.Errorf("unknown dictionary error = %v", err)
+	}
+	if _, err := NewColorNamer(DictionaryCSS, "cie94"); err == nil || !strings.Contains(err.Error(), "invalid name matching") {
+		t.Errorf("unknown match method error = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package palette
+
+import (
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Oklab returns the Oklab coordinates of a color (Björn Ottosson, 2020), with L from 0 to 1
+func Oklab(c colorful.Color) (l, a, b float64) {
+	r, g, bl := c.LinearRgb()
+	lms := [3]float64{
+		0.4122214708*r + 0.5363325363*g + 0.0514459929*bl,
+		0.2119034982*r + 0.6806995451*g + 0.1073969566*bl,
+		0.0883024619*r + 0.2817188376*g + 0.6299787005*bl,
+	}
+	for i := range lms {
+		lms[i] = math.Cbrt(lms[i])
+	}
+	l = 0.2104542553*lms[0] + 0.7936177850*lms[1] - 0.0040720468*lms[2]
+	a = 1.9779984951*lms[0] - 2.4285922050*lms[1] + 0.4505937099*lms[2]
+	b = 0.0259040371*lms[0] + 0.7827717662*lms[1] - 0.8086757660*lms[2]
+	return l, a, b
+}
+
+// FromOklab converts Oklab coordinates to a color, which may be outside the sRGB gamut
+func FromOklab(l, a, b float64) colorful.Color {
+	lms := [3]float64{
+		l + 0.3963377774*a + 0.2158037573*b,
+		l - 0.1055613458*a - 0.0638541728*b,
+		l - 0.0894841775*a - 1.2914855480*b,
+	}
+	for i := range lms {
+		lms[i] = lms[i] * lms[i] * lms[i]
+	}
+	return colorful.LinearRgb(
+		+4.0767416621*lms[0]-3.3077115913*lms[1]+0.2309699292*lms[2],
+		-1.2684380046*lms[0]+2.6097574011*lms[1]-0.3413193965*lms[2],
+		-0.0041960863*lms[0]-0.7034186147*lms[1]+1.7076147010*lms[2],
+	)
+}
+
+// Oklch returns the Oklch coordinates of a color: lightness from 0 to 1, chroma, and hue in degrees
+func Oklch(c colorful.Color) (l, chroma, h float64) {
+	l, a, b := Oklab(c)
+	chroma = math.Hypot(a, b)
+	h = math.Mod(math.Atan2(b, a)*180/math.Pi+360, 360)
+	return l, chroma, h
+}
+
+// FromOklch converts Oklch coordinates to a color in the sRGB gamut, lowering the chroma until it fits
+// so lightness and hue are kept
+func FromOklch(l, chroma, h float64) colorful.Color {
+	l = clamp(l, 0, 1)
+	return reduceChroma(chroma, func(chroma float64) colorful.Color {
+		rad := h * math.Pi / 180
+		return FromOklab(l, chroma*math.Cos(rad), chroma*math.Sin(rad))
+	})
+}
+
+// reduceChroma returns toColor(chroma) if it is in the sRGB gamut, and otherwise the in-gamut color
+// with the highest lower chroma, found by bisection
+func reduceChroma(chroma float64, toColor func(chroma float64) colorful.Color) colorful.Color {
+	if c := toColor(chroma); c.IsValid() {
+		return c
+	}
+	lo, hi := 0.0, chroma
+	for i := 0; i < 20; i++ {
+		mid := (lo + hi) / 2
+		if toColor(mid).IsValid() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return toColor(lo).Clamped()
+}
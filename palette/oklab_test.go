@@ -0,0 +1,82 @@
+package palette
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+func TestOklab(t *testing.T) {
+	// Reference values from Björn Ottosson's Oklab post
+	tests := []struct {
+		hex     string
+		l, a, b float64
+	}{
+		{"#ffffff", 1, 0, 0},
+		{"#000000", 0, 0, 0},
+		{"#ff0000", 0.627955, 0.224863, 0.125846},
+		{"#00ff00", 0.866440, -0.233888, 0.179498},
+		{"#0000ff", 0.452014, -0.032457, -0.311528},
+	}
+	for _, tt := range tests {
+		c, _ := colorful.Hex(tt.hex)
+		l, a, b := Oklab(c)
+		if math.Abs(l-tt.l) > 1e-5 || math.Abs(a-tt.a) > 1e-5 || math.Abs(b-tt.b) > 1e-5 {
+			t.Errorf("Oklab(%s) = (%.6f, %.6f, %.6f), want (%.6f, %.6f, %.6f)", tt.hex, l, a, b, tt.l, tt.a, tt.b)
+		}
+	}
+}
+
+func TestOklabRoundTrip(t *testing.T) {
+	for r := 0; r <= 255; r += 51 {
+		for g := 0; g <= 255; g += 51 {
+			for b := 0; b <= 255; b += 51 {
+				c := colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}
+				if got := FromOklab(Oklab(c)); got.DistanceRgb(c) > 1e-4 {
+					t.Errorf("Oklab round trip of %s gave %s", c.Hex(), got.Hex())
+				}
+				if got := FromOklch(Oklch(c)); got.Hex() != c.Hex() {
+					t.Errorf("Oklch round trip of %s gave %s", c.Hex(), got.Hex())
+				}
+			}
+		}
+	}
+}
+
+func TestFromOklchGamutMapping(t *testing.T) {
+	// Far more chroma than sRGB holds at this lightness: only the chroma gives way
+	const l, h = 0.7, 150.0
+	c := FromOklch(l, 0.5, h)
+	if !c.IsValid() {
+		t.Fatalf("FromOklch() = %v, outside sRGB", c)
+	}
+	gotL, gotC, gotH := Oklch(c)
+	if math.Abs(gotL-l) > 0.005 || math.Abs(gotH-h) > 1 {
+		t.Errorf("FromOklch(%g, 0.5, %g) = (%.3f, %.3f, %.1f), want lightness and hue kept", l, h, gotL, gotC, gotH)
+	}
+	if gotC >= 0.5 || gotC < 0.1 {
+		t.Errorf("chroma = %.3f, want it reduced to the gamut boundary", gotC)
+	}
+}
+
+func TestHarmony(t *testing.T) {
+	base, _ := colorful.Hex("#3366cc")
+	for _, scheme := range HarmonySchemes {
+		colors, err := Harmony(base, scheme, SpaceOklch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(colors) != len(harmonyOffsets[scheme]) {
+			t.Fatalf("%s has %d colors, want %d", scheme, len(colors), len(harmonyOffsets[scheme]))
+		}
+		_, _, baseHue := Oklch(base)
+		for i, c := range colors {
+			_, _, h := Oklch(c)
+			want := math.Mod(baseHue+harmonyOffsets[scheme][i]+360, 360)
+			if HueDistance(h, want) > 1 {
+				t.Errorf("%s color %d hue = %.1f, want %.1f", scheme, i, h, want)
+			}
+		}
+	}
+}
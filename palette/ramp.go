@@ -0,0 +1,55 @@
+// Package palette provides color math on extracted palettes: ramps, contrast,
+// naming and other analyses that work on colors rather than on pixels.
+package palette
+
+import (
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// RampSteps are the tint/shade steps of a ramp, following the 50–900 convention of CSS frameworks
+var RampSteps = []int{50, 100, 200, 300, 400, 500, 600, 700, 800, 900}
+
+// rampMix is how far each step moves from the base color toward white (tints) or black (shades)
+var rampMix = map[int]float64{
+	50: 0.92, 100: 0.80, 200: 0.60, 300: 0.40, 400: 0.20,
+	500: 0,
+	600: 0.18, 700: 0.36, 800: 0.54, 900: 0.72,
+}
+
+// Ramp lightness bounds in HCL, so the lightest tint isn't pure white and the darkest shade isn't pure black
+const (
+	rampLightest = 0.97
+	rampDarkest  = 0.12
+)
+
+// RampColor is one step of a tint/shade ramp
+type RampColor struct {
+	Step int
+	Hex  string
+}
+
+// Ramp builds a 50–900 tint/shade ramp around base, which becomes step 500. Lightness is
+// interpolated in HCL with the hue held constant, and chroma fades toward the light end so
+// tints don't turn neon.
+func Ramp(base colorful.Color) []RampColor {
+	h, c, l := base.Hcl()
+	// A base beyond a bound keeps its lightness at that end, so shades never get lighter than it
+	// and tints never darker
+	lightest, darkest := max(rampLightest, l), min(rampDarkest, l)
+
+	ramp := make([]RampColor, 0, len(RampSteps))
+	for _, step := range RampSteps {
+		mix := rampMix[step]
+		var stepColor colorful.Color
+		switch {
+		case step < 500:
+			stepColor = colorful.Hcl(h, c*(1-0.8*mix), l+(lightest-l)*mix)
+		case step > 500:
+			stepColor = colorful.Hcl(h, c*(1-0.3*mix), l-(l-darkest)*mix)
+		default:
+			stepColor = base
+		}
+		ramp = append(ramp, RampColor{Step: step, Hex: stepColor.Clamped().Hex()})
+	}
+	return ramp
+}
@@ -0,0 +1,49 @@
+package palette
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRamp(t *testing.T) {
+	for _, hex := range []string{"#1f77b4", "#ff0000", "#808080", "#000000", "#ffffff", "#f0e68c"} {
+		base := mustHex(t, hex)
+		ramp := Ramp(base)
+		if len(ramp) != len(RampSteps) {
+			t.Fatalf("Ramp(%s) has %d steps, want %d", hex, len(ramp), len(RampSteps))
+		}
+
+		previous := math.Inf(1)
+		for i, step := range ramp {
+			if step.Step != RampSteps[i] {
+				t.Errorf("Ramp(%s) step %d = %d, want %d", hex, i, step.Step, RampSteps[i])
+			}
+			if step.Step == 500 && step.Hex != hex {
+				t.Errorf("Ramp(%s) 500 = %s, want the base color", hex, step.Hex)
+			}
+			// Lightness falls from 50 to 900, staying short of pure white and black
+			_, _, l := mustHex(t, step.Hex).Hcl()
+			if l > previous+1e-3 {
+				t.Errorf("Ramp(%s) %d is lighter than the step before it", hex, step.Step)
+			}
+			previous = l
+			if step.Step != 500 && hex != "#ffffff" && hex != "#000000" && (step.Hex == "#ffffff" || step.Hex == "#000000") {
+				t.Errorf("Ramp(%s) %d = %s, want short of pure white and black", hex, step.Step, step.Hex)
+			}
+		}
+	}
+}
+
+func TestRampKeepsHue(t *testing.T) {
+	base := mustHex(t, "#1f77b4")
+	h, _, _ := base.Hcl()
+	for _, step := range Ramp(base) {
+		if step.Step < 100 || step.Step > 800 {
+			continue // The ends have too little chroma to hold a hue after rounding
+		}
+		stepHue, _, _ := mustHex(t, step.Hex).Hcl()
+		if d := math.Abs(math.Mod(stepHue-h+540, 360) - 180); d > 5 {
+			t.Errorf("step %d (%s) hue %.1f, want within 5° of %.1f", step.Step, step.Hex, stepHue, h)
+		}
+	}
+}
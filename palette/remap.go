@@ -0,0 +1,156 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// MaxRemapColors is the largest palette an indexed image can hold
+const MaxRemapColors = 256
+
+// ColorPalette converts palette colors into an image color palette, most frequent first
+func ColorPalette(colors map[string]int) (color.Palette, error) {
+	swatches := imageprocessor.SortedSwatches(colors)
+	if len(swatches) == 0 {
+		return nil, fmt.Errorf("palette is empty")
+	}
+	if len(swatches) > MaxRemapColors {
+		return nil, fmt.Errorf("palette has %d colors, indexed images hold at most %d", len(swatches), MaxRemapColors)
+	}
+
+	pal := make(color.Palette, 0, len(swatches))
+	for _, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return nil, err
+		}
+		r, g, b := c.RGB255()
+		pal = append(pal, color.RGBA{R: r, G: g, B: b, A: 255})
+	}
+	return pal, nil
+}
+
+// Remap replaces every pixel of img with the perceptually nearest palette color, measured in Lab.
+// The alpha of translucent images is kept, see remapPalette.
+func Remap(img image.Image, colors map[string]int) (*image.Paletted, error) {
+	pal, err := newRemapPalette(colors, img)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := newNearestMatcher(pal.colors)
+	bounds := img.Bounds()
+	out := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), pal.entries)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			out.SetColorIndex(x-bounds.Min.X, y-bounds.Min.Y, pal.index(matcher.index(c.R, c.G, c.B), c.A))
+		}
+	}
+	return out, nil
+}
+
+// remapPalette is the palette of a remapped image. For opaque images it holds the palette colors.
+// For translucent ones, those are followed by the colors again at as many evenly spaced, lower
+// alpha levels as fit in an indexed image, and finally a fully transparent entry.
+type remapPalette struct {
+	colors  color.Palette // The opaque palette colors
+	levels  int           // Alpha levels above transparent, the last one opaque
+	entries color.Palette // The colors at each alpha level, most opaque first
+}
+
+func newRemapPalette(colors map[string]int, img image.Image) (*remapPalette, error) {
+	pal, err := ColorPalette(colors)
+	if err != nil {
+		return nil, err
+	}
+	if isOpaque(img) {
+		return &remapPalette{colors: pal, levels: 1, entries: pal}, nil
+	}
+	if len(pal) >= MaxRemapColors {
+		return nil, fmt.Errorf("palette has %d colors, translucent indexed images hold at most %d besides transparency", len(pal), MaxRemapColors-1)
+	}
+
+	levels := (MaxRemapColors - 1) / len(pal)
+	entries := append(make(color.Palette, 0, levels*len(pal)+1), pal...)
+	for level := levels - 1; level > 0; level-- {
+		alpha := uint8(255 * level / levels)
+		for _, entry := range pal {
+			c := entry.(color.RGBA)
+			entries = append(entries, color.NRGBA{R: c.R, G: c.G, B: c.B, A: alpha})
+		}
+	}
+	entries = append(entries, color.NRGBA{})
+	return &remapPalette{colors: pal, levels: levels, entries: entries}, nil
+}
+
+// index returns the entry of a palette color at the alpha level nearest to alpha
+func (p *remapPalette) index(colorIndex, alpha uint8) uint8 {
+	level := int(math.Round(float64(alpha) * float64(p.levels) / 255))
+	if level == 0 {
+		return uint8(len(p.entries) - 1)
+	}
+	return uint8((p.levels-level)*len(p.colors) + int(colorIndex))
+}
+
+// isOpaque reports whether every pixel of img is fully opaque
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nearestMatcher finds the nearest palette entry in Lab, caching the answer for each RGB value
+type nearestMatcher struct {
+	labs  [][3]float64
+	cache map[uint32]uint8
+}
+
+func newNearestMatcher(pal color.Palette) *nearestMatcher {
+	labs := make([][3]float64, len(pal))
+	for i, entry := range pal {
+		c, _ := colorful.MakeColor(entry)
+		l, a, b := c.Lab()
+		labs[i] = [3]float64{l, a, b}
+	}
+	return &nearestMatcher{labs: labs, cache: map[uint32]uint8{}}
+}
+
+// index returns the palette index nearest to an 8-bit sRGB color
+func (m *nearestMatcher) index(r, g, b uint8) uint8 {
+	key := uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+	if i, ok := m.cache[key]; ok {
+		return i
+	}
+	l, a, bb := colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}.Lab()
+	i := m.nearestLab(l, a, bb)
+	m.cache[key] = i
+	return i
+}
+
+// nearestLab returns the palette index nearest to a Lab color
+func (m *nearestMatcher) nearestLab(l, a, b float64) uint8 {
+	best, bestDistance := 0, -1.0
+	for i, lab := range m.labs {
+		dl, da, db := l-lab[0], a-lab[1], b-lab[2]
+		distance := dl*dl + da*da + db*db
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return uint8(best)
+}
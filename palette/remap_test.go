@@ -0,0 +1,75 @@
+package palette
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRemap(t *testing.T) {
+	colors := map[string]int{"#ff0000": 2, "#0000ff": 1}
+
+	tests := []struct {
+		name string
+		in   color.NRGBA
+		want color.NRGBA
+	}{
+		{name: "opaque", in: color.NRGBA{R: 200, G: 30, B: 40, A: 255}, want: color.NRGBA{R: 255, A: 255}},
+		{name: "translucent keeps its straight color", in: color.NRGBA{R: 10, G: 20, B: 230, A: 128}, want: color.NRGBA{B: 255, A: 128}},
+		{name: "barely visible", in: color.NRGBA{R: 255, A: 1}, want: color.NRGBA{}},
+		{name: "transparent", in: color.NRGBA{}, want: color.NRGBA{}},
+	}
+
+	img := image.NewNRGBA(image.Rect(10, 10, 10+len(tests), 11))
+	for i, tt := range tests {
+		img.SetNRGBA(10+i, 10, tt.in)
+	}
+	remapped, err := Remap(img, colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remapped.Bounds() != image.Rect(0, 0, len(tests), 1) {
+		t.Errorf("bounds = %v, want the source size from the origin", remapped.Bounds())
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := color.NRGBAModel.Convert(remapped.At(i, 0)).(color.NRGBA)
+			// Alpha is kept to the nearest of the levels that fit in the palette
+			alphaStep := 255.0 / float64((MaxRemapColors-1)/len(colors))
+			if got.R != tt.want.R || got.G != tt.want.G || got.B != tt.want.B || abs(float64(got.A)-float64(tt.want.A)) > alphaStep {
+				t.Errorf("remapped %v to %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapOpaquePalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 250, A: 255})
+	img.Set(1, 0, color.RGBA{G: 250, A: 255})
+
+	remapped, err := Remap(img, map[string]int{"#ff0000": 1, "#00ff00": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remapped.Palette) != 2 {
+		t.Errorf("opaque image palette has %d entries, want only the 2 colors", len(remapped.Palette))
+	}
+}
+
+func TestRemapTooManyColors(t *testing.T) {
+	colors := make(map[string]int, MaxRemapColors)
+	for i := 0; i < MaxRemapColors; i++ {
+		colors[fmt.Sprintf("#0000%02x", i)] = 1
+	}
+	opaque := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	opaque.Set(0, 0, color.Black)
+	if _, err := Remap(opaque, colors); err != nil {
+		t.Errorf("Remap() of an opaque image with %d colors: %v", MaxRemapColors, err)
+	}
+	if _, err := Remap(image.NewNRGBA(image.Rect(0, 0, 1, 1)), colors); err == nil {
+		t.Errorf("Remap() of a transparent image with %d colors succeeded, want no room for transparency", MaxRemapColors)
+	}
+}
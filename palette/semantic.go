@@ -0,0 +1,161 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Semantic roles inferred from a palette
+const (
+	RolePrimary    = "primary"
+	RoleSecondary  = "secondary"
+	RoleBackground = "background"
+	RoleSurface    = "surface"
+	RoleAccent     = "accent"
+)
+
+// SemanticRoles lists the roles in the order they are usually presented
+var SemanticRoles = []string{RolePrimary, RoleSecondary, RoleBackground, RoleSurface, RoleAccent}
+
+// SemanticColor is a palette color assigned to a semantic role
+type SemanticColor struct {
+	Role    string
+	Hex     string
+	Derived bool // The color isn't in the palette but was derived from another role
+}
+
+// semanticCandidate is a palette color with the measures used to score it
+type semanticCandidate struct {
+	hex      string
+	color    colorful.Color
+	coverage float64
+	chroma   float64
+	used     bool
+}
+
+// InferSemanticColors assigns primary, secondary, background, surface and accent roles to a palette.
+//
+// The background is the most covering low-chroma color, and the surface the color closest to it in
+// lightness. Primary and secondary are the colors with the highest coverage weighted by chroma, and
+// the accent is the most saturated remaining color with the highest contrast against the background.
+// When the palette has too few colors, missing roles are derived from the background or primary.
+func InferSemanticColors(colors map[string]int) []SemanticColor {
+	total := float64(imageprocessor.TotalCount(colors))
+	var candidates []*semanticCandidate
+	for _, swatch := range imageprocessor.SortedSwatches(colors) {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			continue
+		}
+		_, chroma, _ := c.Hcl()
+		coverage := 0.0
+		if total > 0 {
+			coverage = float64(swatch.Count) / total
+		}
+		candidates = append(candidates, &semanticCandidate{hex: swatch.Hex, color: c, coverage: coverage, chroma: chroma})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	pick := func(score func(c *semanticCandidate) float64) *semanticCandidate {
+		var best *semanticCandidate
+		bestScore := 0.0
+		for _, c := range candidates {
+			if c.used {
+				continue
+			}
+			if s := score(c); best == nil || s > bestScore {
+				best, bestScore = c, s
+			}
+		}
+		if best != nil {
+			best.used = true
+		}
+		return best
+	}
+
+	background := pick(func(c *semanticCandidate) float64 {
+		return c.coverage * (1.2 - clamp(c.chroma, 0, 1))
+	})
+	_, _, backgroundL := background.color.Hcl()
+
+	primary := pick(func(c *semanticCandidate) float64 {
+		return c.coverage * (0.1 + c.chroma)
+	})
+	secondary := pick(func(c *semanticCandidate) float64 {
+		return c.coverage * (0.1 + c.chroma)
+	})
+	accent := pick(func(c *semanticCandidate) float64 {
+		return c.chroma * ContrastRatio(c.color, background.color)
+	})
+	surface := pick(func(c *semanticCandidate) float64 {
+		_, _, l := c.color.Hcl()
+		return 1 - abs(l-backgroundL)
+	})
+
+	roles := map[string]SemanticColor{
+		RoleBackground: {Role: RoleBackground, Hex: background.hex},
+	}
+	assign := func(role string, c *semanticCandidate, fallback func() colorful.Color) {
+		if c != nil {
+			roles[role] = SemanticColor{Role: role, Hex: c.hex}
+			return
+		}
+		roles[role] = SemanticColor{Role: role, Hex: fallback().Clamped().Hex(), Derived: true}
+	}
+
+	primaryColor := func() colorful.Color {
+		c, _ := colorful.Hex(roles[RolePrimary].Hex)
+		return c
+	}
+	assign(RolePrimary, primary, func() colorful.Color {
+		// Only a background: move its lightness halfway to the opposite end
+		h, c, l := background.color.Hcl()
+		opposite := 1.0
+		if l >= 0.5 {
+			opposite = 0
+		}
+		return colorful.Hcl(h, c, l+(opposite-l)*0.5)
+	})
+	assign(RoleSecondary, secondary, func() colorful.Color {
+		h, c, l := primaryColor().Hcl()
+		return colorful.Hcl(h+30, c, l)
+	})
+	assign(RoleAccent, accent, func() colorful.Color {
+		h, c, l := primaryColor().Hcl()
+		return colorful.Hcl(h+180, c, l)
+	})
+	assign(RoleSurface, surface, func() colorful.Color {
+		// Slightly lift a dark background, slightly dim a light one
+		h, c, l := background.color.Hcl()
+		if l < 0.5 {
+			return colorful.Hcl(h, c, l+0.06)
+		}
+		return colorful.Hcl(h, c, l-0.04)
+	})
+
+	semantic := make([]SemanticColor, 0, len(SemanticRoles))
+	for _, role := range SemanticRoles {
+		semantic = append(semantic, roles[role])
+	}
+	return semantic
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,104 @@
+package palette
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInferSemanticColors(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors map[string]int
+		want   map[string]string
+	}{
+		{
+			name:   "light",
+			colors: map[string]int{"#ffffff": 60, "#1f77b4": 20, "#ff7f0e": 10, "#f0f0f0": 5, "#d62728": 5},
+			want: map[string]string{
+				RoleBackground: "#ffffff",
+				RolePrimary:    "#1f77b4",
+				RoleSecondary:  "#ff7f0e",
+				RoleAccent:     "#d62728",
+				RoleSurface:    "#f0f0f0",
+			},
+		},
+		{
+			name:   "dark",
+			colors: map[string]int{"#121212": 50, "#1e1e1e": 20, "#bb86fc": 15, "#03dac6": 10, "#cf6679": 5},
+			want: map[string]string{
+				RoleBackground: "#121212",
+				RolePrimary:    "#bb86fc",
+				RoleSecondary:  "#03dac6",
+				RoleAccent:     "#cf6679",
+				RoleSurface:    "#1e1e1e",
+			},
+		},
+		{
+			// A saturated color covering most of the image is still primary, not the background
+			name:   "gray background under a vivid majority",
+			colors: map[string]int{"#e02020": 50, "#eeeeee": 40, "#202020": 10},
+			want: map[string]string{
+				RoleBackground: "#eeeeee",
+				RolePrimary:    "#e02020",
+				RoleSecondary:  "#202020",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			semantic := InferSemanticColors(tt.colors)
+			if len(semantic) != len(SemanticRoles) {
+				t.Fatalf("got %d roles, want %d", len(semantic), len(SemanticRoles))
+			}
+			for i, color := range semantic {
+				if color.Role != SemanticRoles[i] {
+					t.Errorf("role %d = %s, want %s", i, color.Role, SemanticRoles[i])
+				}
+				want, ok := tt.want[color.Role]
+				if !ok {
+					continue
+				}
+				if color.Hex != want || color.Derived {
+					t.Errorf("%s = %+v, want %s from the palette", color.Role, color, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInferSemanticColorsDerived(t *testing.T) {
+	if semantic := InferSemanticColors(map[string]int{}); semantic != nil {
+		t.Errorf("InferSemanticColors of an empty palette = %v, want nil", semantic)
+	}
+
+	for _, hex := range []string{"#1f77b4", "#f5f5f5", "#101010"} {
+		roles := make(map[string]SemanticColor)
+		for _, color := range InferSemanticColors(map[string]int{hex: 1}) {
+			roles[color.Role] = color
+		}
+		if background := roles[RoleBackground]; background.Hex != hex || background.Derived {
+			t.Errorf("%s: background = %+v, want the only color", hex, background)
+		}
+		for _, role := range []string{RolePrimary, RoleSecondary, RoleSurface, RoleAccent} {
+			if !roles[role].Derived {
+				t.Errorf("%s: %s = %+v, want it derived", hex, role, roles[role])
+			}
+		}
+
+		// The derived primary moves halfway toward the opposite end of lightness
+		_, _, backgroundL := mustHex(t, hex).Hcl()
+		_, _, primaryL := mustHex(t, roles[RolePrimary].Hex).Hcl()
+		opposite := 1.0
+		if backgroundL >= 0.5 {
+			opposite = 0
+		}
+		if want := backgroundL + (opposite-backgroundL)/2; math.Abs(primaryL-want) > 0.03 {
+			t.Errorf("%s: derived primary %s has lightness %.2f, want %.2f", hex, roles[RolePrimary].Hex, primaryL, want)
+		}
+		// The surface stays close to the background
+		_, _, surfaceL := mustHex(t, roles[RoleSurface].Hex).Hcl()
+		if d := math.Abs(surfaceL - backgroundL); d < 0.02 || d > 0.08 {
+			t.Errorf("%s: derived surface %s is %.2f from the background in lightness", hex, roles[RoleSurface].Hex, d)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Swatch orders
+const (
+	SortFrequency = "frequency" // Most frequent first
+	SortHue       = "hue"       // Around the hue circle, grays last from light to dark
+	SortLuminance = "luminance" // Lightest first
+)
+
+// SortOrders lists the supported swatch orders
+var SortOrders = []string{SortFrequency, SortHue, SortLuminance}
+
+// grayChroma is the HCL chroma below which a color is treated as a gray when sorting by hue
+const grayChroma = 0.08
+
+// SortSwatches returns the colors of a palette in the given order
+func SortSwatches(colors map[string]int, by string) ([]imageprocessor.Swatch, error) {
+	swatches := imageprocessor.SortedSwatches(colors)
+
+	type key struct {
+		gray           bool
+		hue, lightness float64
+	}
+	keys := make(map[string]key, len(swatches))
+	for _, swatch := range swatches {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			return nil, err
+		}
+		h, chroma, l := c.Hcl()
+		keys[swatch.Hex] = key{gray: chroma < grayChroma, hue: h, lightness: l}
+	}
+
+	switch by {
+	case "", SortFrequency:
+		// Already in frequency order
+	case SortHue:
+		sort.SliceStable(swatches, func(i, j int) bool {
+			a, b := keys[swatches[i].Hex], keys[swatches[j].Hex]
+			if a.gray != b.gray {
+				return !a.gray
+			}
+			if a.gray {
+				return a.lightness > b.lightness
+			}
+			return a.hue < b.hue
+		})
+	case SortLuminance:
+		sort.SliceStable(swatches, func(i, j int) bool {
+			return keys[swatches[i].Hex].lightness > keys[swatches[j].Hex].lightness
+		})
+	default:
+		return nil, fmt.Errorf("unknown swatch order %q. Supported orders: %s", by, strings.Join(SortOrders, ", "))
+	}
+	return swatches, nil
+}
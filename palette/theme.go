@@ -0,0 +1,173 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// ANSINames are the names of the 16 ANSI terminal color slots, in slot order
+var ANSINames = [16]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright black", "bright red", "bright green", "bright yellow", "bright blue", "bright magenta", "bright cyan", "bright white",
+}
+
+// ansiHues are the HCL hue targets, in degrees, for the six chromatic ANSI slots (red to cyan)
+var ansiHues = [6]float64{30, 135, 85, 270, 330, 200}
+
+// hueTolerance is how far, in degrees, a palette color's hue may be from a slot's target to be used for it
+const hueTolerance = 35
+
+// minThemeChroma is the chroma below which a palette color is too gray to fill a chromatic slot
+const minThemeChroma = 0.15
+
+// ThemeOptions controls terminal theme generation
+type ThemeOptions struct {
+	Light       bool    // Generate a light theme instead of a dark one
+	MinContrast float64 // Minimum WCAG contrast of the ANSI colors against the background; 0 means 4.5
+}
+
+// Theme is a terminal color scheme
+type Theme struct {
+	Background string
+	Foreground string
+	Cursor     string
+	Selection  string
+	ANSI       [16]string
+}
+
+// GenerateTheme maps a palette onto a terminal theme. The background and foreground come from the
+// darkest and lightest colors (the reverse for light themes). Each chromatic ANSI slot takes the
+// palette color nearest its hue target when one is close enough, and otherwise the target hue with
+// the palette's typical chroma. Every ANSI color is then adjusted to reach the minimum contrast.
+func GenerateTheme(colors map[string]int, opts ThemeOptions) Theme {
+	minContrast := opts.MinContrast
+	if minContrast <= 0 {
+		minContrast = 4.5
+	}
+
+	var swatches []colorful.Color
+	for _, swatch := range imageprocessor.SortedSwatches(colors) {
+		if c, err := colorful.Hex(swatch.Hex); err == nil {
+			swatches = append(swatches, c)
+		}
+	}
+	if len(swatches) == 0 {
+		swatches = []colorful.Color{{R: 0.5, G: 0.5, B: 0.5}}
+	}
+
+	// Background and foreground from the lightness extremes, pushed further apart if needed
+	darkest, lightest := swatches[0], swatches[0]
+	for _, c := range swatches {
+		if _, _, l := c.Hcl(); l < lightnessOf(darkest) {
+			darkest = c
+		}
+		if _, _, l := c.Hcl(); l > lightnessOf(lightest) {
+			lightest = c
+		}
+	}
+	background, foreground := withLightness(darkest, 0.15, math.Min), withLightness(lightest, 0.88, math.Max)
+	if opts.Light {
+		background, foreground = withLightness(lightest, 0.95, math.Max), withLightness(darkest, 0.2, math.Min)
+	}
+	foreground, _ = AdjustLightnessForContrast(foreground, background, math.Max(minContrast, 7))
+
+	// Typical chroma of the palette, used for synthesized slots
+	chroma := 0.0
+	chromatic := 0
+	for _, c := range swatches {
+		if _, ch, _ := c.Hcl(); ch >= minThemeChroma {
+			chroma += ch
+			chromatic++
+		}
+	}
+	if chromatic > 0 {
+		chroma /= float64(chromatic)
+	} else {
+		chroma = 0.5
+	}
+
+	var theme Theme
+	theme.Background = background.Hex()
+	theme.Foreground = foreground.Hex()
+
+	normalL, brightL := 0.55, 0.7
+	if opts.Light {
+		normalL, brightL = 0.45, 0.35
+	}
+
+	for i, target := range ansiHues {
+		base := colorful.Hcl(target, chroma, normalL)
+		if c, ok := nearestHue(swatches, target); ok {
+			h, ch, _ := c.Hcl()
+			base = colorful.Hcl(h, ch, normalL)
+		}
+		h, ch, _ := base.Hcl()
+		normal, _ := AdjustLightnessForContrast(base.Clamped(), background, minContrast)
+		bright, _ := AdjustLightnessForContrast(colorful.Hcl(h, ch*1.1, brightL).Clamped(), background, minContrast)
+		theme.ANSI[i+1] = normal.Hex()
+		theme.ANSI[i+9] = bright.Hex()
+	}
+
+	// Grays: black and bright black sit near the background, white and bright white near the foreground
+	bh, bc, bl := background.Hcl()
+	fh, fc, fl := foreground.Hcl()
+	theme.ANSI[0] = colorful.Hcl(bh, bc, bl+(fl-bl)*0.1).Clamped().Hex()
+	brightBlack, _ := AdjustLightnessForContrast(colorful.Hcl(bh, bc, bl+(fl-bl)*0.4).Clamped(), background, math.Min(minContrast, 3))
+	theme.ANSI[8] = brightBlack.Hex()
+	theme.ANSI[7] = colorful.Hcl(fh, fc, fl-(fl-bl)*0.12).Clamped().Hex()
+	theme.ANSI[15] = foreground.Hex()
+
+	theme.Selection = colorful.Hcl(bh, bc, bl+(fl-bl)*0.2).Clamped().Hex()
+
+	// The cursor uses the most chromatic palette color so it stands out
+	cursor := foreground
+	best := -1.0
+	for _, c := range swatches {
+		if _, ch, _ := c.Hcl(); ch >= minThemeChroma && ch > best {
+			cursor, best = c, ch
+		}
+	}
+	cursor, _ = AdjustLightnessForContrast(cursor, background, minContrast)
+	theme.Cursor = cursor.Hex()
+
+	return theme
+}
+
+// nearestHue returns the sufficiently chromatic color whose hue is closest to target, if within tolerance
+func nearestHue(colors []colorful.Color, target float64) (colorful.Color, bool) {
+	var best colorful.Color
+	bestDistance := math.Inf(1)
+	for _, c := range colors {
+		h, ch, _ := c.Hcl()
+		if ch < minThemeChroma {
+			continue
+		}
+		if d := HueDistance(h, target); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best, bestDistance <= hueTolerance
+}
+
+// HueDistance returns the angular distance between two hues in degrees, from 0 to 180
+func HueDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+func lightnessOf(c colorful.Color) float64 {
+	_, _, l := c.Hcl()
+	return l
+}
+
+// withLightness moves a color's HCL lightness to pick(l, limit), e.g. math.Min to cap it
+func withLightness(c colorful.Color, limit float64, pick func(a, b float64) float64) colorful.Color {
+	h, ch, l := c.Hcl()
+	// Keep theme backgrounds and foregrounds muted
+	return colorful.Hcl(h, math.Min(ch, 0.1), pick(l, limit)).Clamped()
+}
@@ -0,0 +1,97 @@
+package palette
+
+import (
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+var themePalette = map[string]int{"#1a1b26": 50, "#e0def4": 20, "#d7263d": 10, "#2e86ab": 10, "#3bb273": 5, "#f4d35e": 5}
+
+func TestGenerateThemeContrast(t *testing.T) {
+	for _, light := range []bool{false, true} {
+		theme := GenerateTheme(themePalette, ThemeOptions{Light: light})
+		background := mustHex(t, theme.Background)
+		foreground := mustHex(t, theme.Foreground)
+
+		if dark := RelativeLuminance(background) < RelativeLuminance(foreground); dark == light {
+			t.Errorf("light=%v: background %s, foreground %s", light, theme.Background, theme.Foreground)
+		}
+		if ratio := ContrastRatio(foreground, background); ratio < 6.9 {
+			t.Errorf("light=%v: foreground contrast = %.2f, want at least 7", light, ratio)
+		}
+		for i, hex := range theme.ANSI {
+			if i == 0 || i == 8 {
+				continue // black and bright black sit near the background
+			}
+			if ratio := ContrastRatio(mustHex(t, hex), background); ratio < 4.4 {
+				t.Errorf("light=%v: %s %s contrast = %.2f, want at least 4.5", light, ANSINames[i], hex, ratio)
+			}
+		}
+	}
+}
+
+func TestGenerateThemeMinContrast(t *testing.T) {
+	theme := GenerateTheme(themePalette, ThemeOptions{MinContrast: 7})
+	background := mustHex(t, theme.Background)
+	for i := 1; i < 8; i++ {
+		if ratio := ContrastRatio(mustHex(t, theme.ANSI[i]), background); ratio < 6.9 {
+			t.Errorf("%s %s contrast = %.2f, want at least 7", ANSINames[i], theme.ANSI[i], ratio)
+		}
+	}
+}
+
+func TestGenerateThemeHues(t *testing.T) {
+	theme := GenerateTheme(themePalette, ThemeOptions{})
+	// Each chromatic slot keeps the hue of its palette color, or its target hue when none is close
+	want := map[int]string{1: "#d7263d", 2: "#3bb273", 3: "#f4d35e", 4: "#2e86ab"}
+	for slot, source := range want {
+		sh, _, _ := mustHex(t, source).Hcl()
+		for _, i := range []int{slot, slot + 8} {
+			h, _, _ := mustHex(t, theme.ANSI[i]).Hcl()
+			if d := HueDistance(h, sh); d > 10 {
+				t.Errorf("%s %s hue = %.0f, want near %s's %.0f", ANSINames[i], theme.ANSI[i], h, source, sh)
+			}
+		}
+	}
+	for slot, target := range map[int]float64{5: ansiHues[4]} {
+		h, _, _ := mustHex(t, theme.ANSI[slot]).Hcl()
+		if d := HueDistance(h, target); d > 15 {
+			t.Errorf("%s %s hue = %.0f, want near target %.0f", ANSINames[slot], theme.ANSI[slot], h, target)
+		}
+	}
+}
+
+func TestGenerateThemeCursor(t *testing.T) {
+	theme := GenerateTheme(themePalette, ThemeOptions{})
+	// The most chromatic color is the red
+	h, _, _ := mustHex(t, theme.Cursor).Hcl()
+	rh, _, _ := mustHex(t, "#d7263d").Hcl()
+	if d := HueDistance(h, rh); d > 10 {
+		t.Errorf("cursor = %s, want a red like #d7263d", theme.Cursor)
+	}
+}
+
+func TestGenerateThemeEmpty(t *testing.T) {
+	theme := GenerateTheme(map[string]int{}, ThemeOptions{})
+	for i, hex := range theme.ANSI {
+		if _, err := colorful.Hex(hex); err != nil {
+			t.Errorf("%s = %q: %v", ANSINames[i], hex, err)
+		}
+	}
+}
+
+func TestHueDistance(t *testing.T) {
+	tests := []struct{ a, b, want float64 }{
+		{10, 30, 20},
+		{350, 10, 20},
+		{0, 180, 180},
+		{90, 450, 0},
+		{30, 10, 20},
+	}
+	for _, test := range tests {
+		if got := HueDistance(test.a, test.b); got != test.want {
+			t.Errorf("HueDistance(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package palette
+
+import (
+	"colorsage/imageprocessor"
+	"fmt"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Android Palette style roles
+const (
+	RoleDominant     = "Dominant"
+	RoleVibrant      = "Vibrant"
+	RoleLightVibrant = "LightVibrant"
+	RoleDarkVibrant  = "DarkVibrant"
+	RoleMuted        = "Muted"
+	RoleLightMuted   = "LightMuted"
+	RoleDarkMuted    = "DarkMuted"
+)
+
+// VibrantRoles lists the roles in the order they are assigned; the dominant swatch comes first
+var VibrantRoles = []string{RoleDominant, RoleVibrant, RoleLightVibrant, RoleDarkVibrant, RoleMuted, RoleLightMuted, RoleDarkMuted}
+
+// Minimum WCAG contrast of the recommended text colors, as in Android's Palette
+const (
+	minTitleContrast = 3.0
+	minBodyContrast  = 4.5
+)
+
+// Weights of the scoring terms of a target
+const (
+	weightSaturation = 0.24
+	weightLightness  = 0.52
+	weightPopulation = 0.24
+)
+
+// vibrantTarget is the HSL saturation and lightness range a role looks for, with its ideal value
+type vibrantTarget struct {
+	role                            string
+	minSat, targetSat, maxSat       float64
+	minLight, targetLight, maxLight float64
+}
+
+// vibrantTargets are Android Palette's default targets, vibrant ones first so they get the most
+// saturated colors
+var vibrantTargets = []vibrantTarget{
+	{RoleVibrant, 0.35, 1, 1, 0.3, 0.5, 0.7},
+	{RoleLightVibrant, 0.35, 1, 1, 0.55, 0.74, 1},
+	{RoleDarkVibrant, 0.35, 1, 1, 0, 0.26, 0.45},
+	{RoleMuted, 0, 0.3, 0.4, 0.3, 0.5, 0.7},
+	{RoleLightMuted, 0, 0.3, 0.4, 0.55, 0.74, 1},
+	{RoleDarkMuted, 0, 0.3, 0.4, 0, 0.26, 0.45},
+}
+
+// VibrantSwatch is a palette color assigned to a role, with text colors readable on it. Text
+// colors are white or black with the lowest opacity that reaches the contrast, as #rrggbbaa.
+type VibrantSwatch struct {
+	Role       string
+	Hex        string
+	Population int
+	TitleText  string // At least 3:1 contrast
+	BodyText   string // At least 4.5:1 contrast
+}
+
+// ClassifyVibrant assigns the dominant color and Android Palette style vibrant and muted roles to
+// a palette. Each color scores by how close its HSL saturation and lightness are to a role's target,
+// weighted with its population, and fills at most one role. Roles with no color in range are left out.
+func ClassifyVibrant(colors map[string]int) []VibrantSwatch {
+	type candidate struct {
+		swatch            imageprocessor.Swatch
+		color             colorful.Color
+		saturation, light float64
+		used              bool
+	}
+
+	var candidates []*candidate
+	maxPopulation := 0
+	for _, swatch := range imageprocessor.SortedSwatches(colors) {
+		c, err := colorful.Hex(swatch.Hex)
+		if err != nil {
+			continue
+		}
+		_, s, l := c.Hsl()
+		candidates = append(candidates, &candidate{swatch: swatch, color: c, saturation: s, light: l})
+		maxPopulation = max(maxPopulation, swatch.Count)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	roles := []VibrantSwatch{newVibrantSwatch(RoleDominant, candidates[0].swatch, candidates[0].color)}
+	for _, target := range vibrantTargets {
+		var best *candidate
+		bestScore := math.Inf(-1)
+		for _, cand := range candidates {
+			if cand.used ||
+				cand.saturation < target.minSat || cand.saturation > target.maxSat ||
+				cand.light < target.minLight || cand.light > target.maxLight {
+				continue
+			}
+			score := weightSaturation*(1-math.Abs(cand.saturation-target.targetSat)) +
+				weightLightness*(1-math.Abs(cand.light-target.targetLight)) +
+				weightPopulation*float64(cand.swatch.Count)/float64(max(maxPopulation, 1))
+			if score > bestScore {
+				best, bestScore = cand, score
+			}
+		}
+		if best != nil {
+			best.used = true
+			roles = append(roles, newVibrantSwatch(target.role, best.swatch, best.color))
+		}
+	}
+	return roles
+}
+
+func newVibrantSwatch(role string, swatch imageprocessor.Swatch, c colorful.Color) VibrantSwatch {
+	return VibrantSwatch{
+		Role:       role,
+		Hex:        swatch.Hex,
+		Population: swatch.Count,
+		TitleText:  textColor(c, minTitleContrast),
+		BodyText:   textColor(c, minBodyContrast),
+	}
+}
+
+// textColor returns white, or black if white can't reach the contrast, with the lowest opacity that
+// does reach it on the background. If neither can, it returns the opaque one with the higher contrast.
+func textColor(background colorful.Color, minContrast float64) string {
+	white, black := colorful.Color{R: 1, G: 1, B: 1}, colorful.Color{}
+	for _, text := range []colorful.Color{white, black} {
+		if alpha, ok := minTextAlpha(text, background, minContrast); ok {
+			return fmt.Sprintf("%s%02x", text.Hex(), int(math.Round(alpha*255)))
+		}
+	}
+	if ContrastRatio(white, background) >= ContrastRatio(black, background) {
+		return white.Hex() + "ff"
+	}
+	return black.Hex() + "ff"
+}
+
+// minTextAlpha finds the lowest opacity at which text blended over the background reaches the contrast
+func minTextAlpha(text, background colorful.Color, minContrast float64) (float64, bool) {
+	if ContrastRatio(text, background) < minContrast {
+		return 0, false
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 10; i++ {
+		mid := (lo + hi) / 2
+		if ContrastRatio(background.BlendRgb(text, mid), background) >= minContrast {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, true
+}
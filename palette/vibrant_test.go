@@ -0,0 +1,141 @@
+package palette
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestClassifyVibrant(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors map[string]int
+		want   map[string]string // Role to hex
+	}{
+		{
+			name: "every role",
+			colors: map[string]int{
+				"#8a7a7a": 600, // s 0.06, l 0.51
+				"#e02020": 500, // s 0.75, l 0.50
+				"#f08080": 400, // s 0.78, l 0.72
+				"#801010": 300, // s 0.78, l 0.28
+				"#d0c8c8": 200, // s 0.12, l 0.80
+				"#302828": 100, // s 0.09, l 0.17
+			},
+			want: map[string]string{
+				RoleDominant:     "#8a7a7a",
+				RoleVibrant:      "#e02020",
+				RoleLightVibrant: "#f08080",
+				RoleDarkVibrant:  "#801010",
+				RoleMuted:        "#8a7a7a",
+				RoleLightMuted:   "#d0c8c8",
+				RoleDarkMuted:    "#302828",
+			},
+		},
+		{
+			name: "grays fill only muted roles",
+			colors: map[string]int{
+				"#808080": 300,
+				"#c0c0c0": 200,
+				"#404040": 100,
+			},
+			want: map[string]string{
+				RoleDominant:   "#808080",
+				RoleMuted:      "#808080",
+				RoleLightMuted: "#c0c0c0",
+				RoleDarkMuted:  "#404040",
+			},
+		},
+		{
+			// Both reds fit Vibrant; the closer one takes it and the other falls to the next target
+			name: "a color fills one role",
+			colors: map[string]int{
+				"#e02020": 100,
+				"#e84848": 100, // s 0.78, l 0.60
+			},
+			want: map[string]string{
+				RoleDominant:     "#e02020",
+				RoleVibrant:      "#e02020",
+				RoleLightVibrant: "#e84848",
+			},
+		},
+		{
+			name:   "empty",
+			colors: map[string]int{},
+			want:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			var order []string
+			for _, swatch := range ClassifyVibrant(tt.colors) {
+				got[swatch.Role] = swatch.Hex
+				order = append(order, swatch.Role)
+				if swatch.Population != tt.colors[swatch.Hex] {
+					t.Errorf("%s population = %d, want %d", swatch.Role, swatch.Population, tt.colors[swatch.Hex])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("roles = %v, want %v", got, tt.want)
+			}
+			// Roles come in the order of VibrantRoles
+			var wantOrder []string
+			for _, role := range VibrantRoles {
+				if _, ok := tt.want[role]; ok {
+					wantOrder = append(wantOrder, role)
+				}
+			}
+			if !reflect.DeepEqual(order, wantOrder) {
+				t.Errorf("order = %v, want %v", order, wantOrder)
+			}
+		})
+	}
+}
+
+func TestVibrantTextColors(t *testing.T) {
+	tests := []struct {
+		background string
+		wantTitle  string // Text color and its lowest passing opacity
+		wantBody   string
+	}{
+		{"#ffffff", "#000000", "#000000"},
+		{"#000000", "#ffffff", "#ffffff"},
+		{"#e02020", "#ffffff", "#ffffff"},
+		{"#f0e060", "#000000", "#000000"},
+	}
+	for _, tt := range tests {
+		swatches := ClassifyVibrant(map[string]int{tt.background: 1})
+		if len(swatches) == 0 {
+			t.Fatalf("ClassifyVibrant(%s) returned no swatches", tt.background)
+		}
+		swatch := swatches[0]
+		background := mustHex(t, tt.background)
+		for _, text := range []struct {
+			got, want   string
+			minContrast float64
+		}{
+			{swatch.TitleText, tt.wantTitle, minTitleContrast},
+			{swatch.BodyText, tt.wantBody, minBodyContrast},
+		} {
+			if !strings.HasPrefix(text.got, text.want) || len(text.got) != 9 {
+				t.Errorf("text on %s = %s, want %s with an opacity", tt.background, text.got, text.want)
+				continue
+			}
+			// The text blended at its opacity reaches the contrast
+			opacity, err := strconv.ParseUint(text.got[7:], 16, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			alpha := float64(opacity) / 255
+			blended := background.BlendRgb(mustHex(t, text.got[:7]), alpha)
+			if ratio := ContrastRatio(blended, background); ratio < text.minContrast-0.05 {
+				t.Errorf("text %s on %s has contrast %.2f, want at least %.1f", text.got, tt.background, ratio, text.minContrast)
+			}
+		}
+		if swatch.TitleText > swatch.BodyText {
+			t.Errorf("title text %s on %s is more opaque than body text %s", swatch.TitleText, tt.background, swatch.BodyText)
+		}
+	}
+}